@@ -8,16 +8,20 @@ import (
 
 // CLI represents the go-reorder command.
 type CLI struct {
-	Write        bool     `targ:"flag,short=w,desc=Write result to source file instead of stdout"`
-	Check        bool     `targ:"flag,short=c,desc=Check if files are properly ordered (exit 1 if not)"`
-	Diff         bool     `targ:"flag,short=d,desc=Display diff instead of reordered source"`
-	Verbose      bool     `targ:"flag,short=v,desc=Show config and processing details"`
-	Init         bool     `targ:"flag,name=init,desc=Create a default .go-reorder.toml config file"`
-	ListSections bool     `targ:"flag,name=list-sections,desc=List available section names for config"`
-	Config       string   `targ:"flag,name=config,desc=Path to config file"`
-	Mode         string   `targ:"flag,name=mode,desc=Behavior mode (strict|warn|append|drop)"`
-	Exclude      []string `targ:"flag,name=exclude,desc=Exclude files matching pattern (can be repeated)"`
-	Path         string   `targ:"positional,placeholder=PATH,desc=File or directory to process"`
+	Write            bool     `targ:"flag,short=w,desc=Write result to source file instead of stdout"`
+	Check            bool     `targ:"flag,short=c,desc=Check if files are properly ordered (exit 1 if not)"`
+	Diff             bool     `targ:"flag,short=d,desc=Display diff instead of reordered source"`
+	Edits            bool     `targ:"flag,name=edits,desc=Print JSON text edits (LSP TextEdit) instead of reordered source"`
+	Verbose          bool     `targ:"flag,short=v,desc=Show config and processing details"`
+	Init             bool     `targ:"flag,name=init,desc=Create a default .go-reorder.toml config file"`
+	ListSections     bool     `targ:"flag,name=list-sections,desc=List available section names for config"`
+	Config           string   `targ:"flag,name=config,desc=Path to config file"`
+	Mode             string   `targ:"flag,name=mode,desc=Behavior mode (strict|warn|append|drop)"`
+	Exclude          []string `targ:"flag,name=exclude,desc=Exclude files matching pattern (can be repeated)"`
+	AssertIdempotent bool     `targ:"flag,name=assert-idempotent,desc=Fail with a diff if reordering the output again changes it"`
+	Lines            string   `targ:"flag,name=lines,desc=Only reorder declarations within lines START:END of a single file"`
+	LSP              bool     `targ:"flag,name=lsp,desc=Run a language server over stdio instead of processing files"`
+	Path             string   `targ:"positional,placeholder=PATH,desc=File or directory to process"`
 }
 
 // Reorder Go source files.
@@ -35,6 +39,40 @@ func (c *CLI) Run() error {
 		stderr = testCtx.stderr
 	}
 
+	status = c.execute(stdin, stdout, stderr)
+	return nil
+}
+
+// unexported variables.
+var (
+	status  int // Exit code left by Run for main
+	testCtx *testContext
+)
+
+type cliOptions struct {
+	write            bool
+	check            bool
+	diff             bool
+	edits            bool
+	verbose          bool
+	config           string
+	mode             string
+	exclude          []string
+	assertIdempotent bool
+	lines            string     // --lines as given
+	lineRange        *lineRange // Parsed from lines by run
+}
+
+// testContext holds test injection - separate from CLI to avoid targ's zero-value check.
+type testContext struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// execute runs the command selected by the flags.
+// Returns exit code (0 for success, 1 for error or, with --check, unordered files).
+func (c *CLI) execute(stdin io.Reader, stdout, stderr io.Writer) int {
 	// Handle --list-sections
 	if c.ListSections {
 		sections := []string{
@@ -43,36 +81,43 @@ func (c *CLI) Run() error {
 			"exported_types", "exported_funcs",
 			"unexported_consts", "unexported_enums", "unexported_vars",
 			"unexported_types", "unexported_funcs",
+			"test_main", "tests", "benchmarks", "fuzz_tests", "examples", "test_helpers",
+			"exported_generic_funcs", "unexported_generic_funcs",
+			"exported_generic_types", "unexported_generic_types", "cgo_exports",
 			"uncategorized",
 		}
 		_, _ = fmt.Fprintln(stdout, "Available sections for config:")
 		for _, s := range sections {
 			_, _ = fmt.Fprintf(stdout, "  %s\n", s)
 		}
-		return nil
+		return 0
 	}
 
 	// Handle --init
 	if c.Init {
-		exitCode := c.runInit(stdout, stderr)
-		if testCtx != nil {
-			testCtx.exitCode = exitCode
-			return nil
-		}
-		if exitCode != 0 {
-			os.Exit(exitCode)
-		}
-		return nil
+		return c.runInit(stdout, stderr)
 	}
 
 	opts := cliOptions{
-		write:   c.Write,
-		check:   c.Check,
-		diff:    c.Diff,
-		verbose: c.Verbose,
-		config:  c.Config,
-		mode:    c.Mode,
-		exclude: c.Exclude,
+		write:            c.Write,
+		check:            c.Check,
+		diff:             c.Diff,
+		edits:            c.Edits,
+		verbose:          c.Verbose,
+		config:           c.Config,
+		mode:             c.Mode,
+		exclude:          c.Exclude,
+		assertIdempotent: c.AssertIdempotent,
+		lines:            c.Lines,
+	}
+
+	// Handle --lsp, which serves editors in place of the file modes
+	if c.LSP {
+		if c.Path != "" || c.Write || c.Check || c.Diff || c.Edits || c.Lines != "" {
+			_, _ = fmt.Fprintf(stderr, "Error: --lsp takes no path, --write, --check, --diff, --edits or --lines\n")
+			return 1
+		}
+		return runLSP(opts, stdin, stdout, stderr)
 	}
 
 	var files []string
@@ -80,40 +125,7 @@ func (c *CLI) Run() error {
 		files = []string{c.Path}
 	}
 
-	exitCode := run(opts, files, stdin, stdout, stderr)
-
-	if testCtx != nil {
-		testCtx.exitCode = exitCode
-		return nil
-	}
-
-	if exitCode != 0 {
-		os.Exit(exitCode)
-	}
-	return nil
-}
-
-// unexported variables.
-var (
-	testCtx *testContext
-)
-
-type cliOptions struct {
-	write   bool
-	check   bool
-	diff    bool
-	verbose bool
-	config  string
-	mode    string
-	exclude []string
-}
-
-// testContext holds test injection - separate from CLI to avoid targ's zero-value check.
-type testContext struct {
-	stdin    io.Reader
-	stdout   io.Writer
-	stderr   io.Writer
-	exitCode int
+	return run(opts, files, stdin, stdout, stderr)
 }
 
 // runInit creates a default .go-reorder.toml config file.
@@ -150,12 +162,110 @@ order = [
   "uncategorized",
 ]
 
+[test_sections]
+# Order of declaration sections in test files (package foo_test, or files
+# declaring Test*/Benchmark*/Fuzz* functions). Set to [] to use [sections].
+order = [
+  "imports",
+  "main",
+  "init",
+  "test_main",
+  "tests",
+  "benchmarks",
+  "fuzz_tests",
+  "examples",
+  "exported_consts",
+  "exported_enums",
+  "exported_vars",
+  "exported_types",
+  "exported_funcs",
+  "unexported_consts",
+  "unexported_enums",
+  "unexported_vars",
+  "unexported_types",
+  "test_helpers",
+  "unexported_funcs",
+  "uncategorized",
+]
+
 [types]
 # How to order elements within a type group
-type_layout = ["typedef", "constructors", "exported_methods", "unexported_methods"]
+# Add "options" to group functional options (WithTimeout() Option) with their type
+# Add "interface_methods" to group methods by the interface they implement
+# Replace "exported_methods"/"unexported_methods" with "exported_value_methods",
+# "exported_pointer_methods", "unexported_value_methods" and
+# "unexported_pointer_methods" to split methods by receiver kind
+type_layout = ["typedef", "assertions", "constructors", "exported_methods", "unexported_methods"]
 
 # How to order elements within an enum group
-enum_layout = ["typedef", "iota", "exported_methods", "unexported_methods"]
+enum_layout = ["typedef", "iota", "assertions", "exported_methods", "unexported_methods"]
+
+# Which const blocks count as enums
+# iota:        typed const blocks using iota
+# typed_block: const blocks whose specs all share a file-local named type,
+#              and typed consts of that type in other blocks
+enum_detection = ["iota"]
+
+# Function name prefixes that mark constructors
+constructor_prefixes = ["New", "Must"]
+
+# Match constructors on any return value, not just the first
+constructor_any_return = false
+
+# Attach factories returning a file-local interface to that interface
+# (false: attach them to the concrete type named by the function)
+constructor_returns_interface = true
+
+# Type name patterns treated as functional option types, in addition to
+# file-local func types (only used when type_layout includes "options")
+option_types = []
+
+# Grouped type declarations: type ( A struct{}; B int )
+# split:          one declaration per type, dropping the block's doc comment
+# split_with_doc: one declaration per type, the first keeps the block's doc
+# keep:           keep the block intact, placed as its first type
+grouped = "split"
+
+# Method sets of external interfaces for "interface_methods", matched by name
+# alone. Interfaces declared in the file are detected automatically and also
+# matched by signature. Setting this replaces the built-in list (error,
+# fmt.Stringer, io.Reader, io.Writer, io.Closer, sort.Interface, heap.Interface,
+# http.Handler, json.Marshaler, ...)
+# [types.interfaces]
+# "io.Reader" = ["Read"]
+# "sort.Interface" = ["Len", "Less", "Swap"]
+
+[sort]
+# How to order specs in the merged const and var blocks
+# alphabetical: by name
+# dependency:   definitions before uses (ties alphabetical); for vars, warns when
+#               initializers with side effects would run in a different order
+consts = "alphabetical"
+vars = "alphabetical"
+
+# How to order the exported_types and unexported_types sections
+# alphabetical: by name
+# dependency:   each type after the types it refers to (fields, embedded types,
+#               constraints); mutually dependent types stay together
+types = "alphabetical"
+
+# How to order the exported_funcs and unexported_funcs sections
+# alphabetical: by name
+# stepdown:     callers before callees, following the calls within the file
+funcs = "alphabetical"
+
+# How to order tests, benchmarks and fuzz tests in test files
+# alphabetical: by name
+# source:       follow the declaration each one targets (TestFunc, TestType_Method)
+#               in the reordered sibling file (foo.go for foo_test.go)
+tests = "alphabetical"
+
+# How to order examples in test files
+# alphabetical: by name
+# target:       by the identifier each one documents, matching godoc: the package
+#               example first, then identifiers in package order, methods after
+#               their type
+examples = "alphabetical"
 
 [behavior]
 # strict: Error if code has no matching section (default)
@@ -163,6 +273,33 @@ enum_layout = ["typedef", "iota", "exported_methods", "unexported_methods"]
 # append: Silently append unmatched code at end
 # drop:   Discard unmatched code (dangerous!)
 mode = "strict"
+
+[comments]
+# Comments separated from the next declaration by a blank line:
+# attach:      Move with the declaration below (default)
+# drop:        Discard them (directives are kept)
+# keep_at_top: Collect them above the first declaration after the imports
+# regions:     Lines matching region_pattern start regions that are reordered
+#              on their own; declarations never cross regions
+floating = "attach"
+region_pattern = '^//\s*(-{3,}|={3,})'
+
+[spacing]
+# Compaction only joins adjacent declarations with the same keyword: gofmt
+# always separates a type from a func, a const from a type, and so on
+# No blank line between sections
+compact_sections = false
+# No blank line between the declarations of a type or enum group
+# (constructors, methods, ...)
+compact_type_groups = false
+# Blank lines around multi-line specs in merged const/var blocks
+multiline_specs = false
+
+[format]
+# Output is always gofmt-canonical. Also separate multi-line top-level
+# declarations from their neighbors with blank lines (one rule borrowed from
+# gofumpt; gofumpt itself is not run)
+separate_multiline_decls = false
 `
 
 	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/toejough/go-reorder"
+)
+
+// JSON-RPC error codes used by the language server.
+const (
+	lspMethodNotFound = -32601
+	lspInvalidParams  = -32602
+)
+
+// reorderActionKind is the code action kind of "Reorder declarations".
+const reorderActionKind = "source.reorder"
+
+// lspServer speaks the Language Server Protocol over a stream: formatting
+// reorders a document, a code action does the same on demand, and diagnostics
+// flag open documents that are out of order.
+type lspServer struct {
+	in       *bufio.Reader
+	out      io.Writer
+	opts     cliOptions
+	docs     map[string]string // Open documents by URI
+	shutdown bool
+}
+
+// lspMessage is a JSON-RPC request, response or notification.
+type lspMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *lspError       `json:"error,omitempty"`
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lspDiagnostic struct {
+	Range    reorder.Range `json:"range"`
+	Severity int           `json:"severity"`
+	Source   string        `json:"source"`
+	Message  string        `json:"message"`
+}
+
+type lspCodeAction struct {
+	Title       string           `json:"title"`
+	Kind        string           `json:"kind"`
+	Diagnostics []lspDiagnostic  `json:"diagnostics,omitempty"`
+	Edit        lspWorkspaceEdit `json:"edit"`
+}
+
+type lspWorkspaceEdit struct {
+	Changes map[string][]reorder.TextEdit `json:"changes"`
+}
+
+// lspDocumentParams holds the params of the document requests and
+// notifications the server handles; each uses a subset.
+type lspDocumentParams struct {
+	TextDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	} `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+	Context struct {
+		Diagnostics []lspDiagnostic `json:"diagnostics"`
+		Only        []string        `json:"only"`
+	} `json:"context"`
+}
+
+// runLSP serves the language server until the client exits. Returns exit code
+// (0 after an orderly shutdown, 1 otherwise).
+func runLSP(opts cliOptions, stdin io.Reader, stdout, stderr io.Writer) int {
+	server := &lspServer{
+		in:   bufio.NewReader(stdin),
+		out:  stdout,
+		opts: opts,
+		docs: make(map[string]string),
+	}
+
+	for {
+		body, err := readLSPMessage(server.in)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				_, _ = fmt.Fprintf(stderr, "Error: %v\n", err)
+			}
+			return 1
+		}
+
+		var msg lspMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			_, _ = fmt.Fprintf(stderr, "Error: invalid message: %v\n", err)
+			continue
+		}
+
+		if msg.Method == "exit" {
+			if server.shutdown {
+				return 0
+			}
+			return 1
+		}
+
+		if err := server.handle(&msg); err != nil {
+			_, _ = fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+}
+
+// readLSPMessage reads the body of the next message, framed by a
+// Content-Length header.
+func readLSPMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length: %w", err)
+			}
+		}
+	}
+
+	if length < 0 {
+		return nil, errors.New("missing Content-Length header")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// handle answers a request or applies a notification.
+func (s *lspServer) handle(msg *lspMessage) error {
+	var params lspDocumentParams
+	if len(msg.Params) > 0 {
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			if msg.ID == nil {
+				return nil
+			}
+			return s.replyError(msg.ID, lspInvalidParams, err.Error())
+		}
+	}
+	uri := params.TextDocument.URI
+
+	switch msg.Method {
+	case "initialize":
+		return s.reply(msg.ID, map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":           map[string]any{"openClose": true, "change": 1},
+				"documentFormattingProvider": true,
+				"codeActionProvider":         map[string]any{"codeActionKinds": []string{reorderActionKind}},
+			},
+			"serverInfo": map[string]any{"name": "go-reorder"},
+		})
+	case "shutdown":
+		s.shutdown = true
+		return s.reply(msg.ID, nil)
+	case "textDocument/didOpen":
+		s.docs[uri] = params.TextDocument.Text
+		return s.publishDiagnostics(uri)
+	case "textDocument/didChange":
+		// Documents are synced in full, so the last change is the whole text
+		if len(params.ContentChanges) > 0 {
+			s.docs[uri] = params.ContentChanges[len(params.ContentChanges)-1].Text
+		}
+		return s.publishDiagnostics(uri)
+	case "textDocument/didClose":
+		delete(s.docs, uri)
+		return s.notify("textDocument/publishDiagnostics", map[string]any{
+			"uri":         uri,
+			"diagnostics": []lspDiagnostic{},
+		})
+	case "textDocument/formatting":
+		// Documents that don't parse or don't fit the config are left alone;
+		// diagnostics report the latter
+		edits, _ := s.edits(uri)
+		return s.reply(msg.ID, edits)
+	case "textDocument/codeAction":
+		actions := []lspCodeAction{}
+		if wantsAction(params.Context.Only) {
+			if edits, _ := s.edits(uri); len(edits) > 0 {
+				actions = append(actions, lspCodeAction{
+					Title:       "Reorder declarations",
+					Kind:        reorderActionKind,
+					Diagnostics: ownDiagnostics(params.Context.Diagnostics),
+					Edit:        lspWorkspaceEdit{Changes: map[string][]reorder.TextEdit{uri: edits}},
+				})
+			}
+		}
+		return s.reply(msg.ID, actions)
+	}
+
+	// Other notifications need no answer
+	if msg.ID == nil {
+		return nil
+	}
+
+	return s.replyError(msg.ID, lspMethodNotFound, "method not found: "+msg.Method)
+}
+
+// edits returns the edits that reorder an open document.
+func (s *lspServer) edits(uri string) ([]reorder.TextEdit, error) {
+	text, ok := s.docs[uri]
+	if !ok {
+		return nil, fmt.Errorf("document not open: %s", uri)
+	}
+
+	path := documentPath(uri)
+
+	cfg, err := s.config(path)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := reorder.SourceFile(path, text, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	edits, err := reorder.Edits(text, result)
+	if edits == nil {
+		edits = []reorder.TextEdit{}
+	}
+
+	return edits, err
+}
+
+// config returns the config for a document: the --config file when given,
+// or the one discovered from the document's directory.
+func (s *lspServer) config(path string) (*reorder.Config, error) {
+	configPath := s.opts.config
+	if configPath == "" {
+		var err error
+		configPath, err = reorder.FindConfig(filepath.Dir(path))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := reorder.DefaultConfig()
+	if configPath != "" {
+		var err error
+		cfg, err = reorder.LoadConfig(configPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if s.opts.mode != "" {
+		cfg.Behavior.Mode = s.opts.mode
+	}
+
+	return cfg, nil
+}
+
+// publishDiagnostics reports whether an open document is out of order, at its
+// first edit, or why it can't be reordered.
+func (s *lspServer) publishDiagnostics(uri string) error {
+	diagnostics := []lspDiagnostic{}
+
+	edits, err := s.edits(uri)
+
+	var strictErr *reorder.StrictModeError
+	switch {
+	case errors.As(err, &strictErr):
+		diagnostics = append(diagnostics, lspDiagnostic{
+			Severity: 1, // Error
+			Source:   "go-reorder",
+			Message:  strictErr.Error(),
+		})
+	case err == nil && len(edits) > 0:
+		diagnostics = append(diagnostics, lspDiagnostic{
+			Range:    edits[0].Range,
+			Severity: 2, // Warning
+			Source:   "go-reorder",
+			Message:  "declarations are not in the configured order",
+		})
+	}
+
+	return s.notify("textDocument/publishDiagnostics", map[string]any{
+		"uri":         uri,
+		"diagnostics": diagnostics,
+	})
+}
+
+// wantsAction reports whether a code action request filtered by only asks
+// for "Reorder declarations".
+func wantsAction(only []string) bool {
+	if len(only) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(only, func(kind string) bool {
+		return kind == reorderActionKind || strings.HasPrefix(reorderActionKind, kind+".")
+	})
+}
+
+// ownDiagnostics returns the diagnostics published by go-reorder.
+func ownDiagnostics(diagnostics []lspDiagnostic) []lspDiagnostic {
+	return slices.DeleteFunc(diagnostics, func(d lspDiagnostic) bool {
+		return d.Source != "go-reorder"
+	})
+}
+
+// documentPath converts a file URI to a path. Other URIs are used as they are.
+func documentPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+
+	return filepath.FromSlash(u.Path)
+}
+
+func (s *lspServer) reply(id json.RawMessage, result any) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	return s.write(lspMessage{JSONRPC: "2.0", ID: id, Result: data})
+}
+
+func (s *lspServer) replyError(id json.RawMessage, code int, message string) error {
+	return s.write(lspMessage{JSONRPC: "2.0", ID: id, Error: &lspError{Code: code, Message: message}})
+}
+
+func (s *lspServer) notify(method string, params any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	return s.write(lspMessage{JSONRPC: "2.0", Method: method, Params: data})
+}
+
+func (s *lspServer) write(msg lspMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(data), data)
+
+	return err
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/toejough/go-reorder"
+)
+
+// applyTextEdits applies non-overlapping edits in source order to src.
+func applyTextEdits(src string, edits []reorder.TextEdit) string {
+	for i := len(edits) - 1; i >= 0; i-- {
+		src = src[:edits[i].Start] + edits[i].NewText + src[edits[i].End:]
+	}
+
+	return src
+}
+
+// lspInput frames messages as a client would send them.
+func lspInput(t *testing.T, messages ...map[string]any) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	for _, msg := range messages {
+		msg["jsonrpc"] = "2.0"
+		data, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatalf("failed to marshal message: %v", err)
+		}
+		_, _ = fmt.Fprintf(&buf, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	}
+
+	return &buf
+}
+
+// lspOutput reads back the framed messages the server wrote.
+func lspOutput(t *testing.T, out *bytes.Buffer) []lspMessage {
+	t.Helper()
+
+	var messages []lspMessage
+	r := bufio.NewReader(out)
+	for {
+		body, err := readLSPMessage(r)
+		if err != nil {
+			return messages
+		}
+
+		var msg lspMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatalf("invalid message %s: %v", body, err)
+		}
+		messages = append(messages, msg)
+	}
+}
+
+// lspResponse returns the response to the request with id.
+func lspResponse(t *testing.T, messages []lspMessage, id int) lspMessage {
+	t.Helper()
+
+	for _, msg := range messages {
+		if msg.Method == "" && string(msg.ID) == fmt.Sprint(id) {
+			return msg
+		}
+	}
+	t.Fatalf("no response to request %d", id)
+
+	return lspMessage{}
+}
+
+func TestCLILSP(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "test.go")
+	uri := (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+	content := `package test
+
+func helper() {}
+
+const Version = "1.0"
+`
+	document := map[string]any{"uri": uri}
+
+	stdin := lspInput(t,
+		map[string]any{"id": 1, "method": "initialize", "params": map[string]any{}},
+		map[string]any{"method": "initialized", "params": map[string]any{}},
+		map[string]any{"method": "textDocument/didOpen", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri, "languageId": "go", "version": 1, "text": content},
+		}},
+		map[string]any{"id": 2, "method": "textDocument/formatting", "params": map[string]any{
+			"textDocument": document,
+			"options":      map[string]any{"tabSize": 4, "insertSpaces": false},
+		}},
+		map[string]any{"id": 3, "method": "textDocument/codeAction", "params": map[string]any{
+			"textDocument": document,
+			"context":      map[string]any{"diagnostics": []any{}},
+		}},
+		map[string]any{"id": 4, "method": "textDocument/hover", "params": map[string]any{"textDocument": document}},
+		map[string]any{"id": 5, "method": "shutdown"},
+		map[string]any{"method": "exit"},
+	)
+
+	var stdout, stderr bytes.Buffer
+	exitCode := executeCLI([]string{"--lsp"}, stdin, &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", exitCode, stderr.String())
+	}
+
+	messages := lspOutput(t, &stdout)
+
+	// Capabilities
+	var initResult struct {
+		Capabilities struct {
+			DocumentFormattingProvider bool `json:"documentFormattingProvider"`
+			CodeActionProvider         struct {
+				CodeActionKinds []string `json:"codeActionKinds"`
+			} `json:"codeActionProvider"`
+		} `json:"capabilities"`
+	}
+	if err := json.Unmarshal(lspResponse(t, messages, 1).Result, &initResult); err != nil {
+		t.Fatalf("invalid initialize result: %v", err)
+	}
+	if !initResult.Capabilities.DocumentFormattingProvider {
+		t.Error("expected documentFormattingProvider")
+	}
+	if kinds := initResult.Capabilities.CodeActionProvider.CodeActionKinds; len(kinds) != 1 || kinds[0] != "source.reorder" {
+		t.Errorf("expected codeActionKinds [source.reorder], got %v", kinds)
+	}
+
+	// Diagnostics for the open document
+	var diagnostics struct {
+		URI         string          `json:"uri"`
+		Diagnostics []lspDiagnostic `json:"diagnostics"`
+	}
+	for _, msg := range messages {
+		if msg.Method == "textDocument/publishDiagnostics" {
+			if err := json.Unmarshal(msg.Params, &diagnostics); err != nil {
+				t.Fatalf("invalid diagnostics: %v", err)
+			}
+		}
+	}
+	if diagnostics.URI != uri || len(diagnostics.Diagnostics) != 1 {
+		t.Fatalf("expected one diagnostic for %s, got %+v", uri, diagnostics)
+	}
+	if d := diagnostics.Diagnostics[0]; d.Severity != 2 || d.Source != "go-reorder" {
+		t.Errorf("expected a go-reorder warning, got %+v", d)
+	}
+
+	// Formatting edits reorder the document
+	var edits []reorder.TextEdit
+	if err := json.Unmarshal(lspResponse(t, messages, 2).Result, &edits); err != nil {
+		t.Fatalf("invalid formatting result: %v", err)
+	}
+	if len(edits) == 0 {
+		t.Fatal("expected formatting edits")
+	}
+	formatted := applyTextEdits(content, edits)
+	if strings.Index(formatted, "Version") > strings.Index(formatted, "helper") {
+		t.Errorf("expected Version before helper, got:\n%s", formatted)
+	}
+
+	// The code action carries the same edits
+	var actions []lspCodeAction
+	if err := json.Unmarshal(lspResponse(t, messages, 3).Result, &actions); err != nil {
+		t.Fatalf("invalid code action result: %v", err)
+	}
+	if len(actions) != 1 || actions[0].Title != "Reorder declarations" || actions[0].Kind != "source.reorder" {
+		t.Fatalf("expected the reorder code action, got %+v", actions)
+	}
+	if len(actions[0].Edit.Changes[uri]) != len(edits) {
+		t.Errorf("expected %d edits in the code action, got %+v", len(edits), actions[0].Edit)
+	}
+
+	// Unsupported requests get an error
+	if resp := lspResponse(t, messages, 4); resp.Error == nil || resp.Error.Code != lspMethodNotFound {
+		t.Errorf("expected method not found, got %+v", resp)
+	}
+}
+
+func TestCLILSPUsesDiscoveredConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := `[sections]
+order = ["imports", "unexported_funcs", "exported_consts"]
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, ".go-reorder.toml"), []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	path := filepath.Join(tmpDir, "test.go")
+	uri := (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+	content := `package test
+
+func helper() {}
+
+const Version = "1.0"
+`
+
+	stdin := lspInput(t,
+		map[string]any{"id": 1, "method": "initialize", "params": map[string]any{}},
+		map[string]any{"method": "textDocument/didOpen", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri, "languageId": "go", "version": 1, "text": content},
+		}},
+		map[string]any{"id": 2, "method": "textDocument/formatting", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+		}},
+		map[string]any{"id": 3, "method": "shutdown"},
+		map[string]any{"method": "exit"},
+	)
+
+	var stdout, stderr bytes.Buffer
+	exitCode := executeCLI([]string{"--lsp"}, stdin, &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", exitCode, stderr.String())
+	}
+
+	var edits []reorder.TextEdit
+	if err := json.Unmarshal(lspResponse(t, lspOutput(t, &stdout), 2).Result, &edits); err != nil {
+		t.Fatalf("invalid formatting result: %v", err)
+	}
+	formatted := applyTextEdits(content, edits)
+
+	// The discovered config puts unexported funcs first
+	if strings.Index(formatted, "helper") > strings.Index(formatted, "Version") {
+		t.Errorf("expected helper before Version, got:\n%s", formatted)
+	}
+}
+
+func TestCLILSPExitWithoutShutdown(t *testing.T) {
+	stdin := lspInput(t, map[string]any{"method": "exit"})
+
+	var stdout, stderr bytes.Buffer
+	exitCode := executeCLI([]string{"--lsp"}, stdin, &stdout, &stderr)
+
+	if exitCode != 1 {
+		t.Errorf("expected exit code 1, got %d", exitCode)
+	}
+}
+
+func TestCLILSPRejectsFileModes(t *testing.T) {
+	tests := [][]string{
+		{"--lsp", "main.go"},
+		{"--lsp", "--write"},
+		{"--lsp", "--check"},
+		{"--lsp", "--diff"},
+		{"--lsp", "--edits"},
+		{"--lsp", "--lines", "1:2"},
+	}
+
+	for _, args := range tests {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			exitCode := executeCLI(args, strings.NewReader(""), &stdout, &stderr)
+
+			if exitCode != 1 {
+				t.Errorf("expected exit code 1, got %d", exitCode)
+			}
+			if !strings.Contains(stderr.String(), "--lsp takes no path") {
+				t.Errorf("expected an error about --lsp, got %q", stderr.String())
+			}
+		})
+	}
+}
+
+func TestCLIReordersPathNamedLSP(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(origDir) }()
+
+	content := `package test
+
+func helper() {}
+
+func Public() {}
+`
+	if err := os.Mkdir("lsp", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("lsp", "test.go"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	exitCode := executeCLI([]string{"lsp"}, strings.NewReader(""), &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", exitCode, stderr.String())
+	}
+
+	output := stdout.String()
+	if strings.Index(output, "Public") > strings.Index(output, "helper") {
+		t.Errorf("expected the directory named lsp to be reordered, got:\n%s", output)
+	}
+}
@@ -1,7 +1,12 @@
 package main
 
-import "github.com/toejough/targ"
+import (
+	"os"
+
+	"github.com/toejough/targ"
+)
 
 func main() {
 	targ.Run(CLI{})
+	os.Exit(status)
 }
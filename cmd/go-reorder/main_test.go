@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -276,6 +277,54 @@ const Version = "1.0"
 	}
 }
 
+func TestCLITestsFollowSiblingOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	configFile := filepath.Join(tmpDir, "reorder.toml")
+	configContent := `[sort]
+tests = "source"
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	sibling := `package foo
+
+func Alpha() {}
+
+type Zeta struct{}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "foo.go"), []byte(sibling), 0644); err != nil {
+		t.Fatalf("failed to write sibling file: %v", err)
+	}
+
+	inputFile := filepath.Join(tmpDir, "foo_test.go")
+	content := `package foo
+
+import "testing"
+
+func TestZeta(t *testing.T) {}
+
+func TestAlpha(t *testing.T) {}
+`
+	if err := os.WriteFile(inputFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	exitCode := executeCLI([]string{"--config", configFile, inputFile}, nil, &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d; stderr: %s", exitCode, stderr.String())
+	}
+
+	// foo.go puts the Zeta type before the Alpha func, so the tests follow
+	output := stdout.String()
+	if strings.Index(output, "TestZeta") > strings.Index(output, "TestAlpha") {
+		t.Errorf("expected TestZeta before TestAlpha, got:\n%s", output)
+	}
+}
+
 func TestCLIModeFlag(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -560,6 +609,300 @@ const Version = "1.0"
 	}
 }
 
+func TestCLIWarnsAboutInitializerOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	configFile := filepath.Join(tmpDir, "reorder.toml")
+	configContent := `[sort]
+vars = "dependency"
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	content := `package test
+
+import "flag"
+
+var verbose = flag.Bool("v", false, "")
+
+var addr = flag.String("addr", "", "")
+`
+	stdin := strings.NewReader(content)
+	var stdout, stderr bytes.Buffer
+
+	exitCode := executeCLI([]string{"--config", configFile, "-"}, stdin, &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d; stderr: %s", exitCode, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "warning: <stdin>: reordering changes the initialization order") {
+		t.Errorf("expected initialization order warning, got: %s", stderr.String())
+	}
+}
+
+func TestCLIAssertIdempotentFlag(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputFile := filepath.Join(tmpDir, "test.go")
+	content := `package test
+
+func Helper() {}
+
+const Version = "1.0"
+`
+	if err := os.WriteFile(inputFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"--assert-idempotent", inputFile},
+		{"--assert-idempotent", "--check", inputFile},
+		{"--assert-idempotent", "-"},
+	} {
+		var stdout, stderr bytes.Buffer
+		exitCode := executeCLI(args, strings.NewReader(content), &stdout, &stderr)
+
+		if strings.Contains(stderr.String(), "idempotent") {
+			t.Errorf("%v: unexpected idempotency failure: %s", args, stderr.String())
+		}
+		if args[1] != "--check" && exitCode != 0 {
+			t.Errorf("%v: expected exit code 0, got %d; stderr: %s", args, exitCode, stderr.String())
+		}
+	}
+}
+
+func TestAssertIdempotentReportsDiff(t *testing.T) {
+	again := func(src string) (string, error) { return src + "// drift\n", nil }
+
+	err := assertIdempotent("test.go", "package test\n", again)
+	if err == nil {
+		t.Fatal("expected an error when the second pass changes the output")
+	}
+
+	for _, want := range []string{"not idempotent", "--- test.go (first pass)", "+++ test.go (second pass)", "+// drift"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got: %v", want, err)
+		}
+	}
+
+	if err := assertIdempotent("test.go", "package test\n", func(src string) (string, error) { return src, nil }); err != nil {
+		t.Errorf("expected no error for a stable output, got: %v", err)
+	}
+}
+
+func TestCLIEditsFlag(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputFile := filepath.Join(tmpDir, "test.go")
+	content := `package test
+
+func b() {}
+
+func A() {}
+`
+	if err := os.WriteFile(inputFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	exitCode := executeCLI([]string{"--edits", inputFile}, nil, &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d; stderr: %s", exitCode, stderr.String())
+	}
+
+	var output struct {
+		Path  string
+		Edits []struct {
+			Start, End int
+			NewText    string
+		}
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		t.Fatalf("expected JSON output, got %q: %v", stdout.String(), err)
+	}
+
+	if output.Path != inputFile {
+		t.Errorf("expected path %q, got %q", inputFile, output.Path)
+	}
+
+	// Moving A above b leaves b in place
+	applied := content
+	for i := len(output.Edits) - 1; i >= 0; i-- {
+		edit := output.Edits[i]
+		applied = applied[:edit.Start] + edit.NewText + applied[edit.End:]
+	}
+	if want := "package test\n\nfunc A() {}\n\nfunc b() {}\n"; applied != want {
+		t.Errorf("applied edits = %q, want %q", applied, want)
+	}
+
+	// File should not be modified
+	unchanged, _ := os.ReadFile(inputFile)
+	if string(unchanged) != content {
+		t.Error("file should not be modified with --edits")
+	}
+}
+
+func TestCLIEditsStdin(t *testing.T) {
+	content := `package test
+
+func b() {}
+
+func A() {}
+`
+	var stdout, stderr bytes.Buffer
+	exitCode := executeCLI([]string{"--edits", "-"}, strings.NewReader(content), &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d; stderr: %s", exitCode, stderr.String())
+	}
+
+	// Same shape as for files, with "-" for the path
+	var output struct {
+		Path  string
+		Edits []struct{ Start, End int }
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		t.Fatalf("expected JSON output, got %q: %v", stdout.String(), err)
+	}
+	if output.Path != "-" {
+		t.Errorf("expected path %q, got %q", "-", output.Path)
+	}
+	if len(output.Edits) == 0 {
+		t.Error("expected edits")
+	}
+
+	// Already ordered: no output, as for files
+	stdout.Reset()
+	exitCode = executeCLI([]string{"--edits", "-"}, strings.NewReader("package test\n\nfunc Helper() {}\n"), &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d; stderr: %s", exitCode, stderr.String())
+	}
+	if stdout.Len() > 0 {
+		t.Errorf("expected no output, got %q", stdout.String())
+	}
+}
+
+func TestCLIEditsRejectsOtherOutputs(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputFile := filepath.Join(tmpDir, "test.go")
+	content := "package test\n\nfunc b() {}\n\nfunc A() {}\n"
+	if err := os.WriteFile(inputFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	for _, flag := range []string{"--write", "--check", "--diff"} {
+		var stdout, stderr bytes.Buffer
+		exitCode := executeCLI([]string{"--edits", flag, inputFile}, nil, &stdout, &stderr)
+
+		if exitCode != 1 {
+			t.Errorf("%s: expected exit code 1, got %d", flag, exitCode)
+		}
+		if !strings.Contains(stderr.String(), "--edits can't be combined") {
+			t.Errorf("%s: expected an error about --edits, got %q", flag, stderr.String())
+		}
+		if stdout.Len() > 0 {
+			t.Errorf("%s: expected no output, got %q", flag, stdout.String())
+		}
+	}
+
+	// Nothing was written
+	unchanged, _ := os.ReadFile(inputFile)
+	if string(unchanged) != content {
+		t.Error("file should not be modified")
+	}
+}
+
+func TestCLILinesFlag(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputFile := filepath.Join(tmpDir, "test.go")
+	content := `package test
+
+func d() {}
+
+func c() {}
+
+func B() {}
+
+func a() {}
+`
+	if err := os.WriteFile(inputFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	exitCode := executeCLI([]string{"--write", "--lines=5:7", inputFile}, nil, &stdout, &stderr)
+
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d; stderr: %s", exitCode, stderr.String())
+	}
+
+	// Only c and B, on lines 5-7, trade places
+	result, _ := os.ReadFile(inputFile)
+	expected := `package test
+
+func d() {}
+
+func B() {}
+
+func c() {}
+
+func a() {}
+`
+	if string(result) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result)
+	}
+}
+
+func TestCLILinesFlagAssertIdempotent(t *testing.T) {
+	content := `package test
+
+func b() {}
+
+const Version = "1.0"
+`
+	var stdout, stderr bytes.Buffer
+	exitCode := executeCLI([]string{"--lines=3:5", "--assert-idempotent", "-"}, strings.NewReader(content), &stdout, &stderr)
+
+	// The second pass covers the lines the const block grew to
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d; stderr: %s", exitCode, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Version") {
+		t.Errorf("expected reordered source, got %q", stdout.String())
+	}
+}
+
+func TestCLILinesFlagErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputFile := filepath.Join(tmpDir, "test.go")
+	if err := os.WriteFile(inputFile, []byte("package test\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--lines=5", inputFile}, "invalid --lines"},
+		{[]string{"--lines=0:3", inputFile}, "invalid --lines"},
+		{[]string{"--lines=7:3", inputFile}, "invalid --lines"},
+		{[]string{"--lines=1:3", tmpDir}, "--lines needs a single file"},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		exitCode := executeCLI(tt.args, nil, &stdout, &stderr)
+
+		if exitCode != 1 {
+			t.Errorf("%v: expected exit code 1, got %d", tt.args, exitCode)
+		}
+		if !strings.Contains(stderr.String(), tt.want) {
+			t.Errorf("%v: expected error containing %q, got %q", tt.args, tt.want, stderr.String())
+		}
+	}
+}
+
 func TestCLIListSections(t *testing.T) {
 	var stdout, stderr bytes.Buffer
 	exitCode := executeCLI([]string{"--list-sections"}, nil, &stdout, &stderr)
@@ -577,6 +920,9 @@ func TestCLIListSections(t *testing.T) {
 		"exported_types", "exported_funcs",
 		"unexported_consts", "unexported_enums", "unexported_vars",
 		"unexported_types", "unexported_funcs",
+		"test_main", "tests", "benchmarks", "fuzz_tests", "examples", "test_helpers",
+		"exported_generic_funcs", "unexported_generic_funcs",
+		"exported_generic_types", "unexported_generic_types", "cgo_exports",
 		"uncategorized",
 	}
 
@@ -622,6 +968,9 @@ func TestCLIInitCreatesConfig(t *testing.T) {
 	if !strings.Contains(contentStr, "[sections]") {
 		t.Error("expected config to contain [sections]")
 	}
+	if !strings.Contains(contentStr, "[test_sections]") {
+		t.Error("expected config to contain [test_sections]")
+	}
 	if !strings.Contains(contentStr, "[behavior]") {
 		t.Error("expected config to contain [behavior]")
 	}
@@ -1,11 +1,13 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/pmezard/go-difflib/difflib"
@@ -20,19 +22,49 @@ type checkResult struct {
 	sectionsMatch bool     // true if sections are in order but within-section changes needed
 }
 
+// fileEdits is the --edits output for one file, or "-" for stdin.
+type fileEdits struct {
+	Path  string             `json:"path"`
+	Edits []reorder.TextEdit `json:"edits"`
+}
+
+// lineRange is a --lines range of lines, 1-indexed and inclusive.
+type lineRange struct {
+	start, end int
+}
+
+// shifted returns the range holding the same code once reordering has turned
+// src into result. Only lines within the range change, so only its end moves.
+func (r *lineRange) shifted(src, result string) *lineRange {
+	if r == nil {
+		return nil
+	}
+
+	end := r.end + strings.Count(result, "\n") - strings.Count(src, "\n")
+
+	return &lineRange{start: r.start, end: max(end, r.start)}
+}
+
 // analyzeFile checks if a file needs reordering and returns details about the ordering.
-func analyzeFile(path string, cfg *reorder.Config) (*checkResult, error) {
+func analyzeFile(path string, cfg *reorder.Config, opts cliOptions) (*checkResult, error) {
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
 	// Check if reordering would change the file
-	result, err := reorder.SourceWithConfig(string(content), cfg)
+	result, err := reorderSource(path, string(content), cfg, opts.lineRange)
 	if err != nil {
 		return nil, err
 	}
 
+	if opts.assertIdempotent {
+		again := fileReorderer(path, cfg, opts.lineRange.shifted(string(content), result))
+		if err := assertIdempotent(path, result, again); err != nil {
+			return nil, err
+		}
+	}
+
 	if result == string(content) {
 		return nil, nil // No changes needed
 	}
@@ -72,6 +104,71 @@ func analyzeFile(path string, cfg *reorder.Config) (*checkResult, error) {
 	}, nil
 }
 
+// assertIdempotent reorders result a second time with again and fails with a
+// diff of the two passes when the second one changes it.
+func assertIdempotent(name, result string, again func(string) (string, error)) error {
+	second, err := again(result)
+	if err != nil {
+		return fmt.Errorf("reordering the output again: %w", err)
+	}
+
+	if second == result {
+		return nil
+	}
+
+	diff := difflib.UnifiedDiff{
+		A:        difflib.SplitLines(result),
+		B:        difflib.SplitLines(second),
+		FromFile: name + " (first pass)",
+		ToFile:   name + " (second pass)",
+		Context:  3,
+	}
+	text, err := difflib.GetUnifiedDiffString(diff)
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("output is not idempotent, a second pass changes it:\n%s", text)
+}
+
+// fileReorderer returns a function reordering sources as the content of path,
+// within lines when given, without reporting warnings a second time.
+func fileReorderer(path string, cfg *reorder.Config, lines *lineRange) func(string) (string, error) {
+	quiet := *cfg
+	quiet.Warn = nil
+
+	return func(src string) (string, error) {
+		return reorderSource(path, src, &quiet, lines)
+	}
+}
+
+// reorderSource reorders src as the content of path, or of no file when path
+// is empty, touching only the declarations within lines when given.
+func reorderSource(path, src string, cfg *reorder.Config, lines *lineRange) (string, error) {
+	if lines != nil {
+		return reorder.SourceLines(path, src, cfg, lines.start, lines.end)
+	}
+
+	if path == "" {
+		return reorder.SourceWithConfig(src, cfg)
+	}
+
+	return reorder.SourceFile(path, src, cfg)
+}
+
+// parseLineRange parses a --lines value, START:END.
+func parseLineRange(value string) (*lineRange, error) {
+	startText, endText, ok := strings.Cut(value, ":")
+	start, startErr := strconv.Atoi(startText)
+	end, endErr := strconv.Atoi(endText)
+
+	if !ok || startErr != nil || endErr != nil || start < 1 || end < start {
+		return nil, fmt.Errorf("invalid --lines %q, expected START:END with 1 <= START <= END", value)
+	}
+
+	return &lineRange{start: start, end: end}, nil
+}
+
 func processFile(path string, cfg *reorder.Config, opts cliOptions, stdout, stderr io.Writer) (bool, error) {
 	// Read file
 	content, err := os.ReadFile(path)
@@ -79,12 +176,24 @@ func processFile(path string, cfg *reorder.Config, opts cliOptions, stdout, stde
 		return false, err
 	}
 
-	// Reorder
-	result, err := reorder.SourceWithConfig(string(content), cfg)
+	// Reorder, reporting warnings against this file
+	fileCfg := *cfg
+	fileCfg.Warn = func(msg string) {
+		_, _ = fmt.Fprintf(stderr, "warning: %s: %s\n", path, msg)
+	}
+
+	result, err := reorderSource(path, string(content), &fileCfg, opts.lineRange)
 	if err != nil {
 		return false, err
 	}
 
+	if opts.assertIdempotent {
+		again := fileReorderer(path, cfg, opts.lineRange.shifted(string(content), result))
+		if err := assertIdempotent(path, result, again); err != nil {
+			return false, err
+		}
+	}
+
 	// Check if changed
 	changed := result != string(content)
 
@@ -112,6 +221,21 @@ func processFile(path string, cfg *reorder.Config, opts cliOptions, stdout, stde
 		return changed, nil
 	}
 
+	if opts.edits {
+		if changed {
+			edits, err := reorder.Edits(string(content), result)
+			if err != nil {
+				return false, err
+			}
+			data, err := json.Marshal(fileEdits{Path: path, Edits: edits})
+			if err != nil {
+				return false, err
+			}
+			_, _ = fmt.Fprintln(stdout, string(data))
+		}
+		return changed, nil
+	}
+
 	if opts.write {
 		_, _ = fmt.Fprintf(stderr, "%s\n", path)
 		if changed {
@@ -157,13 +281,43 @@ func processStdin(stdin io.Reader, opts cliOptions, stdout, stderr io.Writer) in
 		cfg.Behavior.Mode = opts.mode
 	}
 
+	cfg.Warn = func(msg string) {
+		_, _ = fmt.Fprintf(stderr, "warning: <stdin>: %s\n", msg)
+	}
+
 	// Reorder
-	result, err := reorder.SourceWithConfig(string(content), cfg)
+	result, err := reorderSource("", string(content), cfg, opts.lineRange)
 	if err != nil {
 		_, _ = fmt.Fprintf(stderr, "Error: %v\n", err)
 		return 1
 	}
 
+	if opts.assertIdempotent {
+		again := fileReorderer("", cfg, opts.lineRange.shifted(string(content), result))
+		if err := assertIdempotent("<stdin>", result, again); err != nil {
+			_, _ = fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+
+	if opts.edits {
+		if result == string(content) {
+			return 0
+		}
+		edits, err := reorder.Edits(string(content), result)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		data, err := json.Marshal(fileEdits{Path: "-", Edits: edits})
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		_, _ = fmt.Fprintln(stdout, string(data))
+		return 0
+	}
+
 	// Output to stdout
 	_, _ = fmt.Fprint(stdout, result)
 	return 0
@@ -176,6 +330,21 @@ func run(opts cliOptions, files []string, stdin io.Reader, stdout, stderr io.Wri
 		return 1
 	}
 
+	// --edits replaces the other outputs rather than adding to them
+	if opts.edits && (opts.write || opts.check || opts.diff) {
+		_, _ = fmt.Fprintf(stderr, "Error: --edits can't be combined with --write, --check or --diff\n")
+		return 1
+	}
+
+	if opts.lines != "" {
+		lines, err := parseLineRange(opts.lines)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		opts.lineRange = lines
+	}
+
 	// Handle stdin mode
 	if len(files) == 1 && files[0] == "-" {
 		return processStdin(stdin, opts, stdout, stderr)
@@ -193,6 +362,14 @@ func run(opts cliOptions, files []string, stdin io.Reader, stdout, stderr io.Wri
 		return 1
 	}
 
+	// Line numbers only make sense within one file
+	if opts.lineRange != nil {
+		if info, err := os.Stat(files[0]); len(files) > 1 || err != nil || info.IsDir() {
+			_, _ = fmt.Fprintf(stderr, "Error: --lines needs a single file\n")
+			return 1
+		}
+	}
+
 	// Load config
 	var cfg *reorder.Config
 	var configPath string
@@ -247,7 +424,7 @@ func run(opts cliOptions, files []string, stdin io.Reader, stdout, stderr io.Wri
 	if opts.check {
 		var results []*checkResult
 		for _, f := range goFiles {
-			result, err := analyzeFile(f, cfg)
+			result, err := analyzeFile(f, cfg, opts)
 			if err != nil {
 				_, _ = fmt.Fprintf(stderr, "Error analyzing %s: %v\n", f, err)
 				return 1
@@ -13,8 +13,15 @@ func executeCLI(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
 		stdout: stdout,
 		stderr: stderr,
 	}
+	status = 0
 	defer func() { testCtx = nil }()
 
-	_, _ = targ.Execute(append([]string{"go-reorder"}, args...), CLI{})
-	return testCtx.exitCode
+	// Arguments targ rejects never reach Run
+	result, err := targ.Execute(append([]string{"go-reorder"}, args...), CLI{})
+	if err != nil {
+		_, _ = io.WriteString(stderr, result.Output)
+		return 1
+	}
+
+	return status
 }
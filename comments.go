@@ -0,0 +1,292 @@
+package reorder
+
+import (
+	"go/token"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/dave/dst"
+
+	"github.com/toejough/go-reorder/internal/ast"
+)
+
+// floatingGroups splits a declaration's leading decorations into the comment
+// groups separated from it by a blank line and the part attached to it.
+func floatingGroups(start dst.Decorations) (groups []dst.Decorations, attached dst.Decorations) {
+	split := -1
+	for i := range start {
+		if ast.IsBlankLine(start, i) {
+			split = i
+		}
+	}
+	if split < 0 {
+		return nil, start
+	}
+
+	var group dst.Decorations
+	for i, line := range start[:split+1] {
+		if !ast.IsBlankLine(start, i) {
+			group = append(group, line)
+			continue
+		}
+		if len(group) > 0 {
+			groups = append(groups, group)
+			group = nil
+		}
+	}
+
+	return groups, slices.Clone(start[split+1:])
+}
+
+// joinGroups rebuilds leading decorations from floating comment groups, each
+// followed by a blank line.
+func joinGroups(groups []dst.Decorations) dst.Decorations {
+	var start dst.Decorations
+	for _, group := range groups {
+		start = ast.WithBlankLine(append(start, group...))
+	}
+
+	return start
+}
+
+// detachTrailingComments moves the comment groups that the parser left below the
+// package clause or a declaration, on lines of their own, onto the declaration
+// that follows. They are printed separated from it by a blank line, where a
+// second run would read them as its floating comments. The ones below the last
+// declaration end the file; they are returned for attachTrailingComments. With
+// splitTypes, the same goes for the comments below the last type of a grouped
+// type declaration, which end up below its own declaration once split.
+func detachTrailingComments(file *dst.File, splitTypes bool) dst.Decorations {
+	if len(file.Decls) == 0 {
+		return nil
+	}
+
+	ends := [][]*dst.Decorations{{&file.Decs.Name}}
+	for _, decl := range file.Decls {
+		ends = append(ends, declEnds(decl, splitTypes))
+	}
+
+	var footer dst.Decorations
+	for i, declEnd := range ends {
+		var trailing dst.Decorations
+		for _, end := range declEnd {
+			if split := belowLine(*end); split >= 0 {
+				trailing = append(trailing, (*end)[split:]...)
+				*end = slices.Clone((*end)[:split])
+			}
+		}
+		if len(trailing) == 0 {
+			continue
+		}
+
+		if i == len(file.Decls) {
+			footer = trailing
+			continue
+		}
+
+		groups := ast.DropBlankLines(trailing)
+		if len(groups) > 0 {
+			start := &file.Decls[i].Decorations().Start
+			*start = append(ast.WithBlankLine(groups), *start...)
+		}
+	}
+
+	return footer
+}
+
+// belowLine returns the index of the first decoration in end on a line below
+// the declaration, or -1. A line comment ends its line, so what follows one is
+// below even without a line break between them.
+func belowLine(end dst.Decorations) int {
+	for i, line := range end {
+		if line == "\n" || i > 0 && strings.HasPrefix(end[i-1], "//") {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// dropEmptyDocComments removes the doc comments of decls made only of empty
+// lines, which gofmt removes when printing. Left in place, they would keep
+// declarations apart that the next run joins. The same goes for comments
+// between a declaration's keyword and its parenthesis, whose line break gofmt
+// keeps for one run only.
+func dropEmptyDocComments(decls []dst.Decl) {
+	for _, decl := range decls {
+		start := &decl.Decorations().Start
+		_, doc := floatingGroups(*start)
+
+		if len(doc) > 0 && isEmptyComment(doc) {
+			*start = slices.Clone((*start)[:len(*start)-len(doc)])
+		}
+
+		if genDecl, ok := decl.(*dst.GenDecl); ok && len(genDecl.Decs.Tok) > 0 && isEmptyComment(genDecl.Decs.Tok) {
+			genDecl.Decs.Tok = nil
+		}
+	}
+}
+
+// isEmptyComment reports whether comment lines hold no text.
+func isEmptyComment(lines dst.Decorations) bool {
+	return !slices.ContainsFunc(lines, func(line string) bool {
+		text, ok := strings.CutPrefix(line, "//")
+		return line != "\n" && (!ok || strings.TrimSpace(text) != "")
+	})
+}
+
+// declEnds returns the decorations ending decl, in print order: those of the
+// spec of an unparenthesized declaration (import "fmt"), which the parser also
+// leaves comments below it in, then its own. With splitTypes, those of the last
+// type of a grouped type declaration come first too.
+func declEnds(decl dst.Decl, splitTypes bool) []*dst.Decorations {
+	genDecl, ok := decl.(*dst.GenDecl)
+	switch {
+	case ok && !genDecl.Lparen && len(genDecl.Specs) == 1:
+		return []*dst.Decorations{&genDecl.Specs[0].Decorations().End, &genDecl.Decs.End}
+	case ok && splitTypes && isTypeBlock(genDecl):
+		return []*dst.Decorations{&genDecl.Specs[len(genDecl.Specs)-1].Decorations().End, &genDecl.Decs.End}
+	}
+
+	return []*dst.Decorations{&decl.Decorations().End}
+}
+
+// isTypeBlock reports whether genDecl declares several types in parentheses,
+// the declarations split one per type unless grouped = "keep".
+func isTypeBlock(genDecl *dst.GenDecl) bool {
+	return genDecl.Tok == token.TYPE && genDecl.Lparen && len(genDecl.Specs) > 1
+}
+
+// floatingStarts returns the leading decorations of decls other than imports
+// that may hold floating comments. With splitTypes, those of each type of a
+// grouped type declaration follow its own: they lead the type's declaration
+// once split.
+func floatingStarts(decls []dst.Decl, splitTypes bool) []*dst.Decorations {
+	var starts []*dst.Decorations
+
+	for _, decl := range decls {
+		if isImport(decl) {
+			continue
+		}
+
+		starts = append(starts, &decl.Decorations().Start)
+		if genDecl, ok := decl.(*dst.GenDecl); ok && splitTypes && isTypeBlock(genDecl) {
+			for _, spec := range genDecl.Specs {
+				starts = append(starts, &spec.Decorations().Start)
+			}
+		}
+	}
+
+	return starts
+}
+
+// attachTrailingComments puts the comments that ended the file back below its
+// last declaration.
+func attachTrailingComments(file *dst.File, footer dst.Decorations) {
+	if len(footer) == 0 || len(file.Decls) == 0 {
+		return
+	}
+
+	end := &file.Decls[len(file.Decls)-1].Decorations().End
+	*end = append(*end, footer...)
+}
+
+// isImport reports whether decl is an import declaration.
+func isImport(decl dst.Decl) bool {
+	genDecl, ok := decl.(*dst.GenDecl)
+
+	return ok && genDecl.Tok == token.IMPORT
+}
+
+// dropFloatingComments removes floating comment groups from decls, except the
+// ones holding directives and the ones above imports, which belong to the file.
+// With splitTypes, the types of grouped type declarations lose theirs too.
+func dropFloatingComments(decls []dst.Decl, splitTypes bool) {
+	for _, start := range floatingStarts(decls, splitTypes) {
+		groups, attached := floatingGroups(*start)
+		if len(groups) == 0 {
+			continue
+		}
+
+		kept := slices.DeleteFunc(groups, func(group dst.Decorations) bool {
+			return !slices.ContainsFunc(group, ast.IsDirective)
+		})
+		*start = append(joinGroups(kept), attached...)
+	}
+}
+
+// detachFloatingComments removes floating comment groups from decls, other
+// than imports, and returns them in source order. With splitTypes, the types of
+// grouped type declarations lose theirs too.
+func detachFloatingComments(decls []dst.Decl, splitTypes bool) dst.Decorations {
+	var floating []dst.Decorations
+
+	for _, start := range floatingStarts(decls, splitTypes) {
+		groups, attached := floatingGroups(*start)
+		if len(groups) == 0 {
+			continue
+		}
+
+		floating = append(floating, groups...)
+		*start = attached
+	}
+
+	return joinGroups(floating)
+}
+
+// attachFloatingComments puts floating comment groups above the first
+// declaration after the imports.
+func attachFloatingComments(decls []dst.Decl, floating dst.Decorations) {
+	i := slices.IndexFunc(decls, func(decl dst.Decl) bool { return !isImport(decl) })
+	if len(floating) == 0 || i < 0 {
+		return
+	}
+
+	start := &decls[i].Decorations().Start
+	*start = append(slices.Clone(floating), *start...)
+}
+
+// regions splits decls at the declarations whose floating comments hold a line
+// matching pattern. Each region after the first starts with a marked
+// declaration.
+func regions(decls []dst.Decl, pattern *regexp.Regexp) [][]dst.Decl {
+	var result [][]dst.Decl
+
+	begin := 0
+	for i, decl := range decls {
+		if i > 0 && hasRegionMarker(decl, pattern) {
+			result = append(result, decls[begin:i])
+			begin = i
+		}
+	}
+
+	return append(result, decls[begin:])
+}
+
+// hasRegionMarker reports whether decl's floating comments hold a line matching
+// pattern.
+func hasRegionMarker(decl dst.Decl, pattern *regexp.Regexp) bool {
+	groups, _ := floatingGroups(decl.Decorations().Start)
+	for _, group := range groups {
+		if slices.ContainsFunc(group, pattern.MatchString) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// detachRegionMarker removes the floating comments of a region's first
+// declaration when they mark the region, so they stay at its top.
+func detachRegionMarker(decls []dst.Decl, pattern *regexp.Regexp) dst.Decorations {
+	if len(decls) == 0 || !hasRegionMarker(decls[0], pattern) {
+		return nil
+	}
+
+	start := &decls[0].Decorations().Start
+	groups, attached := floatingGroups(*start)
+	*start = attached
+
+	return joinGroups(groups)
+}
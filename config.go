@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 
 	"github.com/BurntSushi/toml"
 )
@@ -12,17 +14,44 @@ import (
 // Exported constants.
 const (
 	ConfigFileName = ".go-reorder.toml"
+	// DefaultRegionPattern matches banner comments such as "// ---- HTTP ----"
+	// and "// ==== Storage ====".
+	DefaultRegionPattern = `^//\s*(-{3,}|={3,})`
 )
 
 // Exported variables.
 var (
-	ErrInvalidConfig        = errors.New("invalid config")
+	ErrInvalidConfig   = errors.New("invalid config")
+	ValidEnumDetection = map[string]bool{
+		"iota":        true,
+		"typed_block": true,
+	}
 	ValidEnumLayoutElements = map[string]bool{
 		"typedef":            true,
 		"iota":               true,
+		"assertions":         true,
 		"exported_methods":   true,
 		"unexported_methods": true,
 	}
+	ValidExampleSorts = map[string]bool{
+		"alphabetical": true,
+		"target":       true,
+	}
+	ValidFloatingComments = map[string]bool{
+		"attach":      true,
+		"drop":        true,
+		"keep_at_top": true,
+		"regions":     true,
+	}
+	ValidFuncSorts = map[string]bool{
+		"alphabetical": true,
+		"stepdown":     true,
+	}
+	ValidGroupedTypes = map[string]bool{
+		"split":          true,
+		"split_with_doc": true,
+		"keep":           true,
+	}
 	ValidModes = map[string]bool{
 		"strict": true,
 		"warn":   true,
@@ -30,26 +59,53 @@ var (
 		"drop":   true,
 	}
 	ValidSections = map[string]bool{
-		"imports":           true,
-		"main":              true,
-		"init":              true,
-		"exported_consts":   true,
-		"exported_enums":    true,
-		"exported_vars":     true,
-		"exported_types":    true,
-		"exported_funcs":    true,
-		"unexported_consts": true,
-		"unexported_enums":  true,
-		"unexported_vars":   true,
-		"unexported_types":  true,
-		"unexported_funcs":  true,
-		"uncategorized":     true,
+		"imports":                  true,
+		"main":                     true,
+		"init":                     true,
+		"exported_consts":          true,
+		"exported_enums":           true,
+		"exported_vars":            true,
+		"exported_types":           true,
+		"exported_funcs":           true,
+		"unexported_consts":        true,
+		"unexported_enums":         true,
+		"unexported_vars":          true,
+		"unexported_types":         true,
+		"unexported_funcs":         true,
+		"test_main":                true,
+		"tests":                    true,
+		"benchmarks":               true,
+		"fuzz_tests":               true,
+		"examples":                 true,
+		"test_helpers":             true,
+		"exported_generic_funcs":   true,
+		"unexported_generic_funcs": true,
+		"exported_generic_types":   true,
+		"unexported_generic_types": true,
+		"cgo_exports":              true,
+		"uncategorized":            true,
+	}
+	ValidTestSorts = map[string]bool{
+		"alphabetical": true,
+		"source":       true,
+	}
+	ValidValueSorts = map[string]bool{
+		"alphabetical": true,
+		"dependency":   true,
 	}
 	ValidTypeLayoutElements = map[string]bool{
 		"typedef":            true,
+		"assertions":         true,
 		"constructors":       true,
+		"options":            true,
+		"interface_methods":  true,
 		"exported_methods":   true,
 		"unexported_methods": true,
+		// Split by receiver kind, in place of exported_methods/unexported_methods
+		"exported_value_methods":     true,
+		"exported_pointer_methods":   true,
+		"unexported_value_methods":   true,
+		"unexported_pointer_methods": true,
 	}
 )
 
@@ -66,6 +122,30 @@ type BehaviorConfig struct {
 	Mode string
 }
 
+// CommentsConfig controls comments that are not attached to a declaration.
+//
+// A floating comment is a comment group separated from the declaration below
+// it by a blank line, such as "// ---- HTTP helpers ----". Floating selects
+// what happens to them:
+//   - "attach":      Travel with the declaration below (default, also when
+//     empty)
+//   - "drop":        Discard them. Groups holding directives are kept.
+//   - "keep_at_top": Collect them, in source order, above the first
+//     declaration after the imports
+//   - "regions":     Floating comments with a line matching RegionPattern are
+//     region markers. Each marker starts a region that is reordered on its
+//     own; declarations never move across regions, and the markers stay put.
+//     Other floating comments travel with the declaration below.
+type CommentsConfig struct {
+	// Floating controls handling of floating comments.
+	// Valid values: "attach", "drop", "keep_at_top", "regions".
+	Floating string
+
+	// RegionPattern is the regular expression (regexp syntax) a comment line
+	// must match to mark a region. Empty selects DefaultRegionPattern.
+	RegionPattern string
+}
+
 // Config holds all configuration for go-reorder.
 //
 // Example usage:
@@ -82,11 +162,31 @@ type Config struct {
 	// Sections controls the order of declaration groups in the output.
 	Sections SectionsConfig
 
+	// TestSections controls the order of declaration groups in test files.
+	TestSections TestSectionsConfig
+
 	// Types controls how types and enums are laid out within their sections.
 	Types TypesConfig
 
+	// Sort controls how declarations are ordered within their sections.
+	Sort SortConfig
+
 	// Behavior controls error handling for unmatched declarations.
 	Behavior BehaviorConfig
+
+	// Comments controls comments that float between declarations.
+	Comments CommentsConfig
+
+	// Spacing controls blank lines between declarations.
+	Spacing SpacingConfig
+
+	// Format controls how the output is formatted.
+	Format FormatConfig
+
+	// Warn receives warnings about reorders that may change program behavior,
+	// such as side-effecting var initializers running in a different order.
+	// Nil discards them. It is not read from config files.
+	Warn func(msg string)
 }
 
 // Validate checks that the config is valid.
@@ -103,6 +203,17 @@ func (c *Config) Validate() error {
 		seen[section] = true
 	}
 
+	seen = make(map[string]bool)
+	for _, section := range c.TestSections.Order {
+		if !ValidSections[section] {
+			return fmt.Errorf("unknown test section: %q", section)
+		}
+		if seen[section] {
+			return fmt.Errorf("duplicate test section: %q", section)
+		}
+		seen[section] = true
+	}
+
 	// Validate type layout
 	seen = make(map[string]bool)
 	for _, elem := range c.Types.TypeLayout {
@@ -114,6 +225,12 @@ func (c *Config) Validate() error {
 		}
 		seen[elem] = true
 	}
+	for _, prefix := range []string{"exported", "unexported"} {
+		if seen[prefix+"_methods"] && (seen[prefix+"_value_methods"] || seen[prefix+"_pointer_methods"]) {
+			return fmt.Errorf("type layout mixes %q with %q/%q", prefix+"_methods",
+				prefix+"_value_methods", prefix+"_pointer_methods")
+		}
+	}
 
 	// Validate enum layout
 	seen = make(map[string]bool)
@@ -127,13 +244,94 @@ func (c *Config) Validate() error {
 		seen[elem] = true
 	}
 
+	// Validate enum detection
+	seen = make(map[string]bool)
+	for _, rule := range c.Types.EnumDetection {
+		if !ValidEnumDetection[rule] {
+			return fmt.Errorf("unknown enum detection rule: %q (valid: iota, typed_block)", rule)
+		}
+		if seen[rule] {
+			return fmt.Errorf("duplicate enum detection rule: %q", rule)
+		}
+		seen[rule] = true
+	}
+
+	for _, prefix := range c.Types.ConstructorPrefixes {
+		if prefix == "" {
+			return errors.New("empty constructor prefix")
+		}
+	}
+
+	for name, methods := range c.Types.Interfaces {
+		if len(methods) == 0 {
+			return fmt.Errorf("interface %q lists no methods", name)
+		}
+	}
+
+	// An unset value selects the default
+	if c.Types.Grouped != "" && !ValidGroupedTypes[c.Types.Grouped] {
+		return fmt.Errorf("unknown grouped type handling: %q (valid: split, split_with_doc, keep)", c.Types.Grouped)
+	}
+
+	for _, pattern := range c.Types.OptionTypes {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid option type pattern: %q", pattern)
+		}
+	}
+
+	// An unset sort selects the default
+	if c.Sort.Tests != "" && !ValidTestSorts[c.Sort.Tests] {
+		return fmt.Errorf("unknown test sort: %q (valid: alphabetical, source)", c.Sort.Tests)
+	}
+
+	if c.Sort.Consts != "" && !ValidValueSorts[c.Sort.Consts] {
+		return fmt.Errorf("unknown const sort: %q (valid: alphabetical, dependency)", c.Sort.Consts)
+	}
+
+	if c.Sort.Vars != "" && !ValidValueSorts[c.Sort.Vars] {
+		return fmt.Errorf("unknown var sort: %q (valid: alphabetical, dependency)", c.Sort.Vars)
+	}
+
+	if c.Sort.Types != "" && !ValidValueSorts[c.Sort.Types] {
+		return fmt.Errorf("unknown type sort: %q (valid: alphabetical, dependency)", c.Sort.Types)
+	}
+
+	if c.Sort.Funcs != "" && !ValidFuncSorts[c.Sort.Funcs] {
+		return fmt.Errorf("unknown func sort: %q (valid: alphabetical, stepdown)", c.Sort.Funcs)
+	}
+
+	if c.Sort.Examples != "" && !ValidExampleSorts[c.Sort.Examples] {
+		return fmt.Errorf("unknown example sort: %q (valid: alphabetical, target)", c.Sort.Examples)
+	}
+
 	if !ValidModes[c.Behavior.Mode] {
 		return fmt.Errorf("unknown mode: %q (valid: strict, warn, append, drop)", c.Behavior.Mode)
 	}
 
+	if c.Comments.Floating != "" && !ValidFloatingComments[c.Comments.Floating] {
+		return fmt.Errorf("unknown floating comment policy: %q (valid: attach, drop, keep_at_top, regions)",
+			c.Comments.Floating)
+	}
+
+	if _, err := regexp.Compile(c.Comments.RegionPattern); err != nil {
+		return fmt.Errorf("invalid region pattern: %q", c.Comments.RegionPattern)
+	}
+
 	return nil
 }
 
+// FormatConfig controls how the output is formatted.
+//
+// Output is always gofmt-canonical. SeparateMultilineDecls adds a single rule
+// borrowed from gofumpt: multi-line top-level declarations are separated from
+// their neighbors by a blank line. No other gofumpt rule is applied, and
+// gofumpt is not run.
+type FormatConfig struct {
+	// SeparateMultilineDecls puts a blank line between a multi-line top-level
+	// declaration and its neighbors.
+	SeparateMultilineDecls bool
+}
+
 // SectionsConfig controls declaration ordering.
 //
 // Available section names:
@@ -150,37 +348,233 @@ func (c *Config) Validate() error {
 //   - "unexported_vars":   Unexported variable declarations
 //   - "unexported_types":  Unexported type definitions (with constructors and methods)
 //   - "unexported_funcs":  Unexported standalone functions
+//   - "test_main":         TestMain(*testing.M) (test files only)
+//   - "tests":             Test functions (test files only)
+//   - "benchmarks":        Benchmark functions (test files only)
+//   - "fuzz_tests":        Fuzz functions (test files only)
+//   - "examples":          Example functions (test files only)
+//   - "test_helpers":      Functions taking *testing.T/B/F or testing.TB (test files only)
 //   - "uncategorized":     Catch-all for anything not matching other sections
+//
+// Optional sections, not in the default order, split declarations out of the
+// ones above when present:
+//   - "exported_generic_funcs":   Exported functions with type parameters
+//   - "unexported_generic_funcs": Unexported functions with type parameters
+//   - "exported_generic_types":   Exported types with type parameters (with
+//     constructors and methods)
+//   - "unexported_generic_types": Unexported types with type parameters
+//   - "cgo_exports":              Functions exported to C with an //export
+//     directive (cgo files only)
 type SectionsConfig struct {
 	// Order lists section names in the desired output order.
 	// Sections not in this list will be handled according to Behavior.Mode.
 	Order []string
 }
 
+// SortConfig controls ordering within sections.
+//
+// Consts and Vars sort the specs of the merged const and var blocks:
+//   - "alphabetical": By name (default)
+//   - "dependency":   Each spec after the specs its value refers to, ties broken
+//     alphabetically, so definitions come before uses. For vars, Config.Warn
+//     is told when this changes the order of initializers with side effects
+//     (function calls or channel receives), since Go runs independent
+//     initializers in declaration order.
+//
+// Types sorts the exported_types and unexported_types sections:
+//   - "alphabetical": By name (default)
+//   - "dependency":   Each type after the types its definition refers to (field,
+//     embedded and element types, method signatures, type parameter
+//     constraints), so definitions come before uses. Mutually dependent types
+//     stay together, alphabetically.
+//
+// Funcs sorts the exported_funcs and unexported_funcs sections:
+//   - "alphabetical": By name (default)
+//   - "stepdown":     Callers before callees, walking the file's call graph
+//     depth-first from entry points (functions nothing else calls: main, init,
+//     methods, exported functions, unreferenced helpers) in reading order
+//
+// Tests sorts tests, benchmarks and fuzz tests in test files:
+//   - "alphabetical": By name (default)
+//   - "source":       By the position of the declaration each one targets in the
+//     reordered sibling file (foo.go for foo_test.go), using the TestFunc /
+//     TestType_Method naming convention. Only SourceFile knows the sibling;
+//     without one, tests stay alphabetical.
+//
+// Examples sorts example functions in test files:
+//   - "alphabetical": By name (default)
+//   - "target":       By the identifier each one documents (Example, ExampleF,
+//     ExampleT, ExampleT_Method), so file order matches godoc: the package
+//     example first, then identifiers in the order of the reordered package
+//     files (by name without SourceFile), methods after their type
+//
+// An empty value selects the default.
+type SortConfig struct {
+	// Consts selects the order of const specs.
+	// Valid values: "alphabetical", "dependency".
+	Consts string
+
+	// Vars selects the order of var specs.
+	// Valid values: "alphabetical", "dependency".
+	Vars string
+
+	// Types selects the order of type groups.
+	// Valid values: "alphabetical", "dependency".
+	Types string
+
+	// Funcs selects the order of standalone functions.
+	// Valid values: "alphabetical", "stepdown".
+	Funcs string
+
+	// Tests selects the order of test functions.
+	// Valid values: "alphabetical", "source".
+	Tests string
+
+	// Examples selects the order of example functions.
+	// Valid values: "alphabetical", "target".
+	Examples string
+}
+
+// SpacingConfig controls the blank lines between emitted declarations.
+//
+//   - CompactSections:   No blank line between sections (default false): a
+//     section follows the previous one on the next line when its first
+//     declaration has the same keyword as the last one above, e.g. exported
+//     funcs followed by unexported funcs. The imports are always followed by
+//     a blank line.
+//   - CompactTypeGroups: No blank line between the declarations of a type or
+//     enum group that have the same keyword (default false): a constructor is
+//     followed directly by the next constructor or a method. gofmt always puts
+//     a blank line between a typedef and its constructor.
+//   - MultilineSpecs:    A blank line before and after specs in merged const
+//     and var blocks whose values span several lines (default false)
+type SpacingConfig struct {
+	// CompactSections drops the blank line between sections whose adjoining
+	// declarations have the same keyword.
+	CompactSections bool
+
+	// CompactTypeGroups drops the blank lines between the declarations of a
+	// type or enum group that have the same keyword.
+	CompactTypeGroups bool
+
+	// MultilineSpecs surrounds multi-line specs in merged blocks with blank
+	// lines.
+	MultilineSpecs bool
+}
+
+// TestSectionsConfig controls declaration ordering in test files.
+//
+// A file is a test file when its package name ends in _test or it declares a
+// TestMain, Test*, Benchmark* or Fuzz* function with the signature go test
+// expects. SourceFile (and the CLI) also treat any file named *_test.go as
+// one. Test files use this order instead of SectionsConfig; an empty order
+// makes them use SectionsConfig too. Test functions are only sorted into the
+// test sections in test files.
+type TestSectionsConfig struct {
+	// Order lists section names in the desired output order for test files.
+	Order []string
+}
+
 // TypesConfig controls how types and enums are laid out internally.
 //
 // TypeLayout elements control type group ordering:
 //   - "typedef":            The type definition itself (type Foo struct{})
+//   - "assertions":         Interface compliance assertions (var _ io.Reader = (*Foo)(nil))
 //   - "constructors":       Functions matching New*TypeName (e.g., NewFoo, NewMockFoo)
+//   - "options":            Functional options returning the type (e.g., WithTimeout() Option)
+//   - "interface_methods":  Methods implementing an interface, grouped per interface
+//     in the interface's method order (e.g., Len, Less, Swap for sort.Interface)
 //   - "exported_methods":   Exported methods on the type
 //   - "unexported_methods": Unexported methods on the type
+//   - "exported_value_methods", "exported_pointer_methods",
+//     "unexported_value_methods", "unexported_pointer_methods": Methods split by
+//     receiver kind (func (t T) vs func (t *T)), used in place of
+//     "exported_methods"/"unexported_methods" (the two can't be mixed)
 //
 // EnumLayout elements control enum group ordering:
 //   - "typedef":            The enum type definition (type Status int)
-//   - "iota":               The associated iota const block
+//   - "iota":               The associated const blocks (iota or typed), in source order
+//   - "assertions":         Interface compliance assertions (var _ fmt.Stringer = Status(0))
 //   - "exported_methods":   Exported methods (e.g., String())
 //   - "unexported_methods": Unexported methods
 //
+// EnumDetection rules control which const blocks are treated as enums:
+//   - "iota":        Typed const blocks using iota (const ( A T = iota; B ))
+//   - "typed_block": Const blocks whose specs all share a file-local named type
+//     (const ( OK Status = "ok"; Err Status = "err" )), and typed consts of
+//     that type in other blocks
+//
 // Constructor matching: Functions are matched as constructors if they:
-//   - Are named New + TypeName (e.g., NewUser for type User)
-//   - Are named New + Prefix + TypeName (e.g., NewMockUser for type User)
-//   - Return *TypeName or TypeName as first return value
+//   - Start with one of ConstructorPrefixes (default "New" and "Must", also when
+//     nil)
+//   - Return TypeName, *TypeName or a generic instantiation (*TypeName[T]) as the
+//     first return value, or as any return value with ConstructorAnyReturn
+//
+// Factories returning a file-local interface attach to the interface with
+// ConstructorReturnsInterface (set by DefaultConfig and LoadConfig). Without it
+// they attach to the concrete type named by the function instead
+// (NewOsRunEnv -> osRunEnv), or stay standalone.
+//
+// Option matching: With "options" in TypeLayout, functions whose first return
+// is a file-local func type (type Option func(*Server)) are grouped with that
+// type. OptionTypes adds name patterns for option types of other kinds
+// (e.g., "*Option" for type ServerOption interface{ apply(*Server) }).
+//
+// Interface matching: With "interface_methods" in TypeLayout, a type implements
+// an interface when it declares every method in the interface's method set.
+// Interfaces declared in the file are detected automatically and matched by
+// method name and signature; Interfaces lists the method names of external
+// ones (default: common standard library interfaces such as io.Reader,
+// fmt.Stringer and sort.Interface), which are matched by name alone.
+// Larger interfaces claim their methods first. Setting Interfaces replaces
+// the defaults.
+//
+// Grouped controls grouped type declarations (type ( A struct{}; B int )):
+//   - "split":          Each type gets its own declaration and the block's doc
+//     comment is dropped (default, also when empty)
+//   - "split_with_doc": As split, with the block's doc comment carried to the
+//     declaration of its first type
+//   - "keep":           The block stays intact, sorted and placed as its first
+//     type. The groups of the other types, holding their constructors and
+//     methods, follow it in block order.
 type TypesConfig struct {
 	// TypeLayout orders elements within each type group.
 	TypeLayout []string
 
 	// EnumLayout orders elements within each enum group.
 	EnumLayout []string
+
+	// EnumDetection lists the rules used to recognize enum const blocks.
+	// Valid values: "iota", "typed_block". Nil selects the default, "iota";
+	// an empty list detects no enums.
+	EnumDetection []string
+
+	// ConstructorPrefixes lists function name prefixes that mark constructors
+	// (e.g., "New", "Must", "Parse", "From"). Nil selects the default.
+	ConstructorPrefixes []string
+
+	// ConstructorAnyReturn matches constructors on any return position, not just
+	// the first (e.g., func NewFoo() (error, *Foo)).
+	ConstructorAnyReturn bool
+
+	// ConstructorReturnsInterface attaches factories returning a file-local
+	// interface to the interface rather than to the concrete type they name.
+	// DefaultConfig and LoadConfig set it; a Config built by hand leaves it
+	// off unless set.
+	ConstructorReturnsInterface bool
+
+	// OptionTypes lists glob patterns (path.Match syntax) of type names treated
+	// as functional option types, in addition to file-local func types.
+	OptionTypes []string
+
+	// Interfaces maps external interfaces (e.g., "io.Reader") to their method
+	// names in declaration order, for the "interface_methods" layout element.
+	// Only names are known, so types implement them by method name alone.
+	Interfaces map[string][]string
+
+	// Grouped controls grouped type declarations.
+	// Valid values: "split", "split_with_doc", "keep".
+	Grouped string
 }
 
 // DefaultConfig returns the default configuration.
@@ -204,9 +598,34 @@ func DefaultConfig() *Config {
 				"uncategorized",
 			},
 		},
+		TestSections: TestSectionsConfig{
+			Order: []string{
+				"imports",
+				"main",
+				"init",
+				"test_main",
+				"tests",
+				"benchmarks",
+				"fuzz_tests",
+				"examples",
+				"exported_consts",
+				"exported_enums",
+				"exported_vars",
+				"exported_types",
+				"exported_funcs",
+				"unexported_consts",
+				"unexported_enums",
+				"unexported_vars",
+				"unexported_types",
+				"test_helpers",
+				"unexported_funcs",
+				"uncategorized",
+			},
+		},
 		Types: TypesConfig{
 			TypeLayout: []string{
 				"typedef",
+				"assertions",
 				"constructors",
 				"exported_methods",
 				"unexported_methods",
@@ -214,13 +633,55 @@ func DefaultConfig() *Config {
 			EnumLayout: []string{
 				"typedef",
 				"iota",
+				"assertions",
 				"exported_methods",
 				"unexported_methods",
 			},
+			EnumDetection:               []string{"iota"},
+			ConstructorPrefixes:         []string{"New", "Must"},
+			ConstructorReturnsInterface: true,
+			Interfaces:                  DefaultInterfaces(),
+			Grouped:                     "split",
+		},
+		Sort: SortConfig{
+			Consts:   "alphabetical",
+			Vars:     "alphabetical",
+			Types:    "alphabetical",
+			Funcs:    "alphabetical",
+			Tests:    "alphabetical",
+			Examples: "alphabetical",
 		},
 		Behavior: BehaviorConfig{
 			Mode: "strict",
 		},
+		Comments: CommentsConfig{
+			Floating:      "attach",
+			RegionPattern: DefaultRegionPattern,
+		},
+	}
+}
+
+// DefaultInterfaces returns the method sets of the standard library interfaces
+// recognized by the "interface_methods" type layout element.
+func DefaultInterfaces() map[string][]string {
+	return map[string][]string{
+		"error":                    {"Error"},
+		"fmt.Stringer":             {"String"},
+		"fmt.GoStringer":           {"GoString"},
+		"io.Reader":                {"Read"},
+		"io.Writer":                {"Write"},
+		"io.Closer":                {"Close"},
+		"io.Seeker":                {"Seek"},
+		"io.ReaderAt":              {"ReadAt"},
+		"io.WriterTo":              {"WriteTo"},
+		"io.ReaderFrom":            {"ReadFrom"},
+		"sort.Interface":           {"Len", "Less", "Swap"},
+		"heap.Interface":           {"Len", "Less", "Swap", "Push", "Pop"},
+		"http.Handler":             {"ServeHTTP"},
+		"json.Marshaler":           {"MarshalJSON"},
+		"json.Unmarshaler":         {"UnmarshalJSON"},
+		"encoding.TextMarshaler":   {"MarshalText"},
+		"encoding.TextUnmarshaler": {"UnmarshalText"},
 	}
 }
 
@@ -285,15 +746,75 @@ func LoadConfig(path string) (*Config, error) {
 	if fileCfg.Behavior.Mode != "" {
 		cfg.Behavior.Mode = fileCfg.Behavior.Mode
 	}
+	if fileCfg.Comments.Floating != "" {
+		cfg.Comments.Floating = fileCfg.Comments.Floating
+	}
+	if fileCfg.Comments.RegionPattern != "" {
+		cfg.Comments.RegionPattern = fileCfg.Comments.RegionPattern
+	}
+	if fileCfg.Format.SeparateMultilineDecls != nil {
+		cfg.Format.SeparateMultilineDecls = *fileCfg.Format.SeparateMultilineDecls
+	}
+	if fileCfg.Spacing.CompactSections != nil {
+		cfg.Spacing.CompactSections = *fileCfg.Spacing.CompactSections
+	}
+	if fileCfg.Spacing.CompactTypeGroups != nil {
+		cfg.Spacing.CompactTypeGroups = *fileCfg.Spacing.CompactTypeGroups
+	}
+	if fileCfg.Spacing.MultilineSpecs != nil {
+		cfg.Spacing.MultilineSpecs = *fileCfg.Spacing.MultilineSpecs
+	}
 	if fileCfg.Sections.Order != nil {
 		cfg.Sections.Order = fileCfg.Sections.Order
 	}
+	if fileCfg.TestSections.Order != nil {
+		cfg.TestSections.Order = fileCfg.TestSections.Order
+	}
 	if fileCfg.Types.TypeLayout != nil {
 		cfg.Types.TypeLayout = fileCfg.Types.TypeLayout
 	}
 	if fileCfg.Types.EnumLayout != nil {
 		cfg.Types.EnumLayout = fileCfg.Types.EnumLayout
 	}
+	if fileCfg.Types.EnumDetection != nil {
+		cfg.Types.EnumDetection = fileCfg.Types.EnumDetection
+	}
+	if fileCfg.Types.ConstructorPrefixes != nil {
+		cfg.Types.ConstructorPrefixes = fileCfg.Types.ConstructorPrefixes
+	}
+	if fileCfg.Types.ConstructorAnyReturn != nil {
+		cfg.Types.ConstructorAnyReturn = *fileCfg.Types.ConstructorAnyReturn
+	}
+	if fileCfg.Types.ConstructorReturnsInterface != nil {
+		cfg.Types.ConstructorReturnsInterface = *fileCfg.Types.ConstructorReturnsInterface
+	}
+	if fileCfg.Types.OptionTypes != nil {
+		cfg.Types.OptionTypes = fileCfg.Types.OptionTypes
+	}
+	if fileCfg.Types.Interfaces != nil {
+		cfg.Types.Interfaces = fileCfg.Types.Interfaces
+	}
+	if fileCfg.Types.Grouped != "" {
+		cfg.Types.Grouped = fileCfg.Types.Grouped
+	}
+	if fileCfg.Sort.Consts != "" {
+		cfg.Sort.Consts = fileCfg.Sort.Consts
+	}
+	if fileCfg.Sort.Vars != "" {
+		cfg.Sort.Vars = fileCfg.Sort.Vars
+	}
+	if fileCfg.Sort.Types != "" {
+		cfg.Sort.Types = fileCfg.Sort.Types
+	}
+	if fileCfg.Sort.Funcs != "" {
+		cfg.Sort.Funcs = fileCfg.Sort.Funcs
+	}
+	if fileCfg.Sort.Tests != "" {
+		cfg.Sort.Tests = fileCfg.Sort.Tests
+	}
+	if fileCfg.Sort.Examples != "" {
+		cfg.Sort.Examples = fileCfg.Sort.Examples
+	}
 
 	// Validate the merged config
 	if err := cfg.Validate(); err != nil {
@@ -307,18 +828,54 @@ type fileBehaviorConfig struct {
 	Mode string
 }
 
+type fileCommentsConfig struct {
+	Floating      string
+	RegionPattern string `toml:"region_pattern"`
+}
+
 // fileConfig mirrors Config but uses pointers/nil to detect unset values.
 type fileConfig struct {
-	Sections fileSectionsConfig
-	Types    fileTypesConfig
-	Behavior fileBehaviorConfig
+	Sections     fileSectionsConfig
+	TestSections fileSectionsConfig `toml:"test_sections"`
+	Types        fileTypesConfig
+	Sort         fileSortConfig
+	Behavior     fileBehaviorConfig
+	Comments     fileCommentsConfig
+	Spacing      fileSpacingConfig
+	Format       fileFormatConfig
+}
+
+type fileFormatConfig struct {
+	SeparateMultilineDecls *bool `toml:"separate_multiline_decls"`
 }
 
 type fileSectionsConfig struct {
 	Order []string
 }
 
+type fileSortConfig struct {
+	Consts   string
+	Vars     string
+	Types    string
+	Funcs    string
+	Tests    string
+	Examples string
+}
+
+type fileSpacingConfig struct {
+	CompactSections   *bool `toml:"compact_sections"`
+	CompactTypeGroups *bool `toml:"compact_type_groups"`
+	MultilineSpecs    *bool `toml:"multiline_specs"`
+}
+
 type fileTypesConfig struct {
-	TypeLayout []string `toml:"type_layout"`
-	EnumLayout []string `toml:"enum_layout"`
+	TypeLayout                  []string            `toml:"type_layout"`
+	EnumLayout                  []string            `toml:"enum_layout"`
+	EnumDetection               []string            `toml:"enum_detection"`
+	ConstructorPrefixes         []string            `toml:"constructor_prefixes"`
+	ConstructorAnyReturn        *bool               `toml:"constructor_any_return"`
+	ConstructorReturnsInterface *bool               `toml:"constructor_returns_interface"`
+	OptionTypes                 []string            `toml:"option_types"`
+	Interfaces                  map[string][]string `toml:"interfaces"`
+	Grouped                     string              `toml:"grouped"`
 }
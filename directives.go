@@ -0,0 +1,100 @@
+package reorder
+
+import (
+	"go/token"
+	"slices"
+	"strings"
+
+	"github.com/dave/dst"
+
+	"github.com/toejough/go-reorder/internal/ast"
+)
+
+// detachGenerateDirectives removes the //go:generate lines from decls and
+// returns them in source order, as one comment group. Go runs them in file
+// order, so they are gathered into a block rather than moved with their
+// declarations.
+func detachGenerateDirectives(decls []dst.Decl) dst.Decorations {
+	var lines dst.Decorations
+
+	isGenerate := func(line string) bool { return strings.HasPrefix(line, "//go:generate") }
+	detach := func(decs *dst.Decorations) {
+		if !slices.ContainsFunc(*decs, isGenerate) {
+			return
+		}
+
+		var kept dst.Decorations
+		for _, line := range *decs {
+			switch {
+			case isGenerate(line):
+				lines = append(lines, line)
+			// The blank line after a removed group goes with it
+			case line == "\n" && (len(kept) == 0 || ast.IsBlankLine(kept, len(kept)-1)):
+			default:
+				kept = append(kept, line)
+			}
+		}
+		*decs = kept
+	}
+
+	// Only the comments around declarations and their specs: the ones in
+	// function bodies stay where they are
+	for _, decl := range decls {
+		detach(&decl.Decorations().Start)
+		if genDecl, ok := decl.(*dst.GenDecl); ok {
+			for _, spec := range genDecl.Specs {
+				detach(&spec.Decorations().Start)
+				detach(&spec.Decorations().End)
+			}
+		}
+		detach(&decl.Decorations().End)
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	return append(lines, "\n")
+}
+
+// detachHeaderDirectives removes the comment groups that sit between the package
+// clause and the first declaration, separated from it by a blank line, when they
+// hold directives. They belong to the file rather than to that declaration.
+// Imports always stay first, so only files without imports need this.
+func detachHeaderDirectives(decls []dst.Decl) dst.Decorations {
+	if len(decls) == 0 {
+		return nil
+	}
+	if genDecl, ok := decls[0].(*dst.GenDecl); ok && genDecl.Tok == token.IMPORT {
+		return nil
+	}
+
+	start := &decls[0].Decorations().Start
+
+	// Everything up to the last blank line is detached from the declaration
+	split := -1
+	for i := range *start {
+		if ast.IsBlankLine(*start, i) {
+			split = i
+		}
+	}
+	if split < 0 || !slices.ContainsFunc((*start)[:split], ast.IsDirective) {
+		return nil
+	}
+
+	header := slices.Clone((*start)[:split+1])
+	*start = slices.Clone((*start)[split+1:])
+
+	return header
+}
+
+// attachHeaderDirectives puts header comment groups back above the first
+// declaration.
+func attachHeaderDirectives(decls []dst.Decl, header dst.Decorations) {
+	if len(header) == 0 || len(decls) == 0 {
+		return
+	}
+
+	start := &decls[0].Decorations().Start
+	*start = append(slices.Clone(header), *start...)
+}
@@ -0,0 +1,207 @@
+package reorder
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// TextEdit replaces the bytes [Start, End) of a source with NewText. Edits
+// cover whole lines, and as JSON they are Language Server Protocol TextEdits
+// with the byte offsets added.
+type TextEdit struct {
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// Range is the span of a TextEdit, as in the Language Server Protocol.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Position is a zero-indexed line and character, counted in UTF-16 code units
+// as in the Language Server Protocol.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// SourceEdits reorders src like SourceWithConfig and returns the edits that
+// turn src into the result.
+func SourceEdits(src string, cfg *Config) ([]TextEdit, error) {
+	result, err := SourceWithConfig(src, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return Edits(src, result)
+}
+
+// Edits returns the edits that turn src into result, src reordered. They are
+// computed from declaration moves: declarations that keep their place are left
+// alone, and the others are deleted and inserted whole, so editors keep the
+// cursors, folds and undo history of the code that did not move. Edits are in
+// source order and do not overlap.
+//
+// Example:
+//
+//	edits, err := reorder.Edits(src, result)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	// Apply from the last edit to the first, so offsets stay valid
+//	for i := len(edits) - 1; i >= 0; i-- {
+//	    src = src[:edits[i].Start] + edits[i].NewText + src[edits[i].End:]
+//	}
+func Edits(src, result string) ([]TextEdit, error) {
+	before, err := declChunks(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse source: %w", err)
+	}
+
+	after, err := declChunks(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse result: %w", err)
+	}
+
+	// offsets[i] is where chunk i of src starts
+	offsets := make([]int, len(before)+1)
+	for i, chunk := range before {
+		offsets[i+1] = offsets[i] + len(chunk)
+	}
+
+	lines := lineStarts(src)
+
+	var edits []TextEdit
+
+	matcher := difflib.NewMatcherWithJunk(before, after, false, nil)
+	for _, op := range matcher.GetOpCodes() {
+		if op.Tag == 'e' {
+			continue
+		}
+
+		start, end := offsets[op.I1], offsets[op.I2]
+		newText := strings.Join(after[op.J1:op.J2], "")
+
+		// Lines the chunks share at either end need no edit
+		prefix, suffix := commonLines(src[start:end], newText)
+		start += prefix
+		end -= suffix
+		newText = newText[prefix : len(newText)-suffix]
+
+		if start == end && newText == "" {
+			continue
+		}
+
+		edits = append(edits, TextEdit{
+			Start: start,
+			End:   end,
+			Range: Range{
+				Start: position(src, lines, start),
+				End:   position(src, lines, end),
+			},
+			NewText: newText,
+		})
+	}
+
+	return edits, nil
+}
+
+// declChunks splits Go source into line-aligned chunks: the text before the
+// first declaration, each declaration with its doc comment, and the text
+// between declarations.
+func declChunks(src string) ([]string, error) {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	// Chunk boundaries; declarations sharing a line share a chunk
+	bounds := []int{0}
+	add := func(offset int) {
+		if offset > bounds[len(bounds)-1] {
+			bounds = append(bounds, offset)
+		}
+	}
+
+	for _, decl := range file.Decls {
+		start := fset.Position(docStart(decl)).Offset
+		add(strings.LastIndexByte(src[:start], '\n') + 1)
+
+		end := fset.Position(decl.End()).Offset
+		if newline := strings.IndexByte(src[end:], '\n'); newline >= 0 {
+			add(end + newline + 1)
+		} else {
+			add(len(src))
+		}
+	}
+	add(len(src))
+
+	chunks := make([]string, 0, len(bounds)-1)
+	for i := 1; i < len(bounds); i++ {
+		chunks = append(chunks, src[bounds[i-1]:bounds[i]])
+	}
+
+	return chunks, nil
+}
+
+// commonLines returns the lengths of the whole lines a and b start with and
+// end with in common, without overlapping.
+func commonLines(a, b string) (prefix, suffix int) {
+	for {
+		line := strings.IndexByte(a[prefix:], '\n')
+		if line < 0 || !strings.HasPrefix(b[prefix:], a[prefix:prefix+line+1]) {
+			break
+		}
+		prefix += line + 1
+	}
+
+	for {
+		restA, restB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+		if !strings.HasSuffix(restA, "\n") {
+			break
+		}
+
+		line := strings.LastIndexByte(restA[:len(restA)-1], '\n') + 1
+		if !strings.HasSuffix(restB, restA[line:]) {
+			break
+		}
+		suffix += len(restA) - line
+	}
+
+	return prefix, suffix
+}
+
+// lineStarts returns the offsets where the lines of src start.
+func lineStarts(src string) []int {
+	starts := []int{0}
+	for i := range len(src) {
+		if src[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+
+	return starts
+}
+
+// position converts a byte offset of src into a line and UTF-16 character.
+func position(src string, lines []int, offset int) Position {
+	line := 0
+	for line+1 < len(lines) && lines[line+1] <= offset {
+		line++
+	}
+
+	return Position{
+		Line:      line,
+		Character: len(utf16.Encode([]rune(src[lines[line]:offset]))),
+	}
+}
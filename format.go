@@ -0,0 +1,135 @@
+package reorder
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+)
+
+// parseFile parses and decorates Go source. Unlike decorator.Parse it stops at
+// syntax errors: decorating the partial syntax tree go/parser returns for them
+// can panic.
+func parseFile(src any) (*dst.File, error) {
+	_, file, err := decorateFile(src)
+
+	return file, err
+}
+
+// decorateFile is parseFile, also returning the decorator, which maps the
+// nodes of the file back to their positions in src.
+func decorateFile(src any) (*decorator.Decorator, *dst.File, error) {
+	dec := decorator.NewDecorator(token.NewFileSet())
+
+	file, err := parser.ParseFile(dec.Fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	decorated, err := dec.DecorateFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return dec, decorated, nil
+}
+
+// printFile renders a reordered file in canonical form, with the configured
+// format rules.
+func printFile(file *dst.File, cfg *Config) (string, error) {
+	var buf bytes.Buffer
+
+	res := decorator.NewRestorer()
+
+	err := res.Fprint(&buf, file)
+	if err != nil {
+		return "", fmt.Errorf("failed to print: %w", err)
+	}
+
+	out, err := formatSource(buf.Bytes(), cfg.Format)
+	if err != nil {
+		return "", fmt.Errorf("failed to format: %w", err)
+	}
+
+	return string(out), nil
+}
+
+// formatSource formats src like gofmt, then applies the extra rules of cfg.
+func formatSource(src []byte, cfg FormatConfig) ([]byte, error) {
+	out, err := format.Source(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.SeparateMultilineDecls {
+		out, err = separateMultilineDecls(out)
+	}
+
+	return out, err
+}
+
+// separateMultilineDecls puts a blank line between adjacent top-level
+// declarations when either spans several lines, as gofumpt does.
+func separateMultilineDecls(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	line := func(pos token.Pos) int { return fset.Position(pos).Line }
+	multiline := func(decl ast.Decl) bool { return line(decl.Pos()) != line(decl.End()) }
+
+	// Byte offsets of the lines that need a blank line above them
+	var offsets []int
+	for i := 1; i < len(file.Decls); i++ {
+		prev, next := file.Decls[i-1], file.Decls[i]
+		if line(docStart(next)) == line(prev.End())+1 && (multiline(prev) || multiline(next)) {
+			offsets = append(offsets, fset.Position(docStart(next)).Offset)
+		}
+	}
+	if len(offsets) == 0 {
+		return src, nil
+	}
+
+	out := make([]byte, 0, len(src)+len(offsets))
+	begin := 0
+	for _, offset := range offsets {
+		// Declarations start at the beginning of their line
+		offset = bytes.LastIndexByte(src[:offset], '\n') + 1
+		out = append(out, src[begin:offset]...)
+		out = append(out, '\n')
+		begin = offset
+	}
+	out = append(out, src[begin:]...)
+
+	return out, nil
+}
+
+// docStart returns where a declaration or spec starts, its doc comment included.
+func docStart(node ast.Node) token.Pos {
+	var doc *ast.CommentGroup
+
+	switch node := node.(type) {
+	case *ast.GenDecl:
+		doc = node.Doc
+	case *ast.FuncDecl:
+		doc = node.Doc
+	case *ast.ValueSpec:
+		doc = node.Doc
+	case *ast.TypeSpec:
+		doc = node.Doc
+	}
+
+	if doc != nil {
+		return doc.Pos()
+	}
+
+	return node.Pos()
+}
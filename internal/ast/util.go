@@ -2,11 +2,14 @@
 package ast
 
 import (
+	"bytes"
 	"go/token"
 	"slices"
+	"strings"
 	"unicode"
 
 	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
 	"github.com/dave/dst/dstutil"
 )
 
@@ -28,6 +31,8 @@ func ExtractTypeName(expr dst.Expr) string {
 		return typeExpr.Name
 	case *dst.SelectorExpr:
 		return typeExpr.Sel.Name
+	case *dst.ParenExpr:
+		return ExtractTypeName(typeExpr.X)
 	case *dst.StarExpr:
 		return ExtractTypeName(typeExpr.X)
 	case *dst.IndexExpr:
@@ -48,6 +53,126 @@ func ExtractReceiverTypeName(recv *dst.FieldList) string {
 	return ExtractTypeName(recv.List[0].Type)
 }
 
+// IsPointerReceiver reports whether a method receiver is a pointer (func (t *T) M()).
+func IsPointerReceiver(recv *dst.FieldList) bool {
+	if recv == nil || len(recv.List) == 0 {
+		return false
+	}
+
+	typeExpr := recv.List[0].Type
+	for {
+		paren, ok := typeExpr.(*dst.ParenExpr)
+		if !ok {
+			break
+		}
+		typeExpr = paren.X
+	}
+
+	_, ok := typeExpr.(*dst.StarExpr)
+
+	return ok
+}
+
+// IsGenericFunc reports whether a function declares type parameters.
+func IsGenericFunc(fn *dst.FuncDecl) bool {
+	return fn.Type.TypeParams != nil && len(fn.Type.TypeParams.List) > 0
+}
+
+// IsGenericType reports whether a type declaration declares type parameters.
+// A grouped declaration is judged by its first type.
+func IsGenericType(decl *dst.GenDecl) bool {
+	if len(decl.Specs) == 0 {
+		return false
+	}
+
+	tspec, ok := decl.Specs[0].(*dst.TypeSpec)
+
+	return ok && tspec.TypeParams != nil && len(tspec.TypeParams.List) > 0
+}
+
+// IsCgoImport reports whether a declaration imports the cgo pseudo-package "C".
+// Its doc comment is the cgo preamble.
+func IsCgoImport(decl dst.Decl) bool {
+	genDecl, ok := decl.(*dst.GenDecl)
+	if !ok || genDecl.Tok != token.IMPORT {
+		return false
+	}
+
+	return slices.ContainsFunc(genDecl.Specs, func(spec dst.Spec) bool {
+		ispec, ok := spec.(*dst.ImportSpec)
+		return ok && ispec.Path.Value == `"C"`
+	})
+}
+
+// CgoExportName returns the C name a function is exported under with an
+// //export directive in its doc comment, or empty string if it has none.
+func CgoExportName(fn *dst.FuncDecl) string {
+	for _, comment := range fn.Decs.Start {
+		if name, ok := strings.CutPrefix(comment, "//export "); ok {
+			return strings.TrimSpace(name)
+		}
+	}
+
+	return ""
+}
+
+// IsDirective reports whether a comment line is a directive rather than prose,
+// following go/ast: //line, //extern and //export, or //name:arg forms such as
+// //go:generate, //go:embed and //nolint:errcheck.
+func IsDirective(line string) bool {
+	text, ok := strings.CutPrefix(line, "//")
+	if !ok {
+		return false
+	}
+
+	for _, prefix := range []string{"line ", "extern ", "export "} {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+
+	colon := strings.Index(text, ":")
+	if colon <= 0 || colon+1 >= len(text) {
+		return false
+	}
+
+	for _, r := range text[:colon+2] {
+		if r != ':' && (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsBlankLine reports whether the line break at decs[i] leaves a blank line. A
+// line comment ends its own line, so a break after one does; the break after a
+// block comment only ends the comment's line.
+func IsBlankLine(decs dst.Decorations, i int) bool {
+	return decs[i] == "\n" && (i == 0 || !strings.HasPrefix(decs[i-1], "/*"))
+}
+
+// DropBlankLines returns decs without the line breaks that leave blank lines.
+func DropBlankLines(decs dst.Decorations) dst.Decorations {
+	var kept dst.Decorations
+	for i, line := range decs {
+		if !IsBlankLine(decs, i) {
+			kept = append(kept, line)
+		}
+	}
+
+	return kept
+}
+
+// WithBlankLine returns decs followed by a blank line.
+func WithBlankLine(decs dst.Decorations) dst.Decorations {
+	if len(decs) > 0 && strings.HasPrefix(decs[len(decs)-1], "/*") {
+		decs = append(decs, "\n")
+	}
+
+	return append(decs, "\n")
+}
+
 // ContainsIota checks if an expression contains the iota identifier.
 func ContainsIota(expr dst.Expr) bool {
 	if expr == nil {
@@ -90,6 +215,46 @@ func IsIotaBlock(decl *dst.GenDecl) bool {
 	return false
 }
 
+// IsTypedBlock checks if a parenthesized const block declares every spec with
+// the same explicit, package-local named type (e.g. string-valued enums).
+func IsTypedBlock(decl *dst.GenDecl) bool {
+	if decl.Tok != token.CONST || !decl.Lparen || len(decl.Specs) == 0 {
+		return false
+	}
+
+	typeName := ""
+
+	for _, spec := range decl.Specs {
+		vspec, ok := spec.(*dst.ValueSpec)
+		if !ok {
+			return false
+		}
+
+		name := SpecTypeName(vspec)
+		if name == "" || (typeName != "" && name != typeName) {
+			return false
+		}
+
+		typeName = name
+	}
+
+	return true
+}
+
+// SpecTypeName returns the package-local named type a const or var spec
+// declares explicitly, or "" for untyped specs and qualified types.
+func SpecTypeName(spec *dst.ValueSpec) string {
+	if spec.Type == nil {
+		return ""
+	}
+
+	if _, qualified := spec.Type.(*dst.SelectorExpr); qualified {
+		return ""
+	}
+
+	return ExtractTypeName(spec.Type)
+}
+
 // ExtractEnumType extracts the type name from an enum const block.
 func ExtractEnumType(decl *dst.GenDecl) string {
 	if len(decl.Specs) == 0 {
@@ -103,3 +268,136 @@ func ExtractEnumType(decl *dst.GenDecl) string {
 
 	return ExtractTypeName(vspec.Type)
 }
+
+// pureBuiltins lists predeclared functions and types whose calls have no side effects.
+var pureBuiltins = map[string]bool{
+	"append": true, "cap": true, "complex": true, "imag": true, "len": true,
+	"make": true, "max": true, "min": true, "new": true, "real": true,
+	"bool": true, "byte": true, "complex64": true, "complex128": true, "error": true,
+	"float32": true, "float64": true, "int": true, "int8": true, "int16": true,
+	"int32": true, "int64": true, "rune": true, "string": true, "uint": true,
+	"uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+}
+
+// ReferencedNames returns the set of identifiers an expression refers to. Selected
+// names (Sel in x.Sel) and declared names (struct fields, interface methods,
+// parameters and results) are excluded since they never name package-level
+// identifiers.
+func ReferencedNames(expr dst.Expr) map[string]bool {
+	names := make(map[string]bool)
+	if expr == nil {
+		return names
+	}
+
+	dstutil.Apply(expr, func(c *dstutil.Cursor) bool {
+		if _, ok := c.Parent().(*dst.SelectorExpr); ok && c.Name() == "Sel" {
+			return false
+		}
+
+		if _, ok := c.Parent().(*dst.Field); ok && c.Name() == "Names" {
+			return false
+		}
+
+		if ident, ok := c.Node().(*dst.Ident); ok {
+			names[ident.Name] = true
+		}
+
+		return true
+	}, nil)
+
+	return names
+}
+
+// HasSideEffects reports whether evaluating an expression may have side effects:
+// it calls something other than a builtin or a conversion to a predeclared type,
+// or receives from a channel. Function literal bodies are not evaluated and are
+// ignored.
+func HasSideEffects(expr dst.Expr) bool {
+	if expr == nil {
+		return false
+	}
+
+	found := false
+
+	dstutil.Apply(expr, func(c *dstutil.Cursor) bool {
+		switch node := c.Node().(type) {
+		case *dst.FuncLit:
+			return false
+		case *dst.UnaryExpr:
+			if node.Op == token.ARROW {
+				found = true
+			}
+		case *dst.CallExpr:
+			fun := node.Fun
+			for {
+				paren, ok := fun.(*dst.ParenExpr)
+				if !ok {
+					break
+				}
+				fun = paren.X
+			}
+
+			switch fn := fun.(type) {
+			case *dst.Ident:
+				found = found || !pureBuiltins[fn.Name]
+			case *dst.ArrayType, *dst.MapType, *dst.ChanType, *dst.FuncType, *dst.InterfaceType:
+				// Conversion to a composite type
+			default:
+				found = true
+			}
+		}
+
+		return !found
+	}, nil)
+
+	return found
+}
+
+// IsMultiline reports whether an expression prints on more than one line. Line
+// breaks recorded in its decorations only count where gofmt keeps them, and
+// function literals count when gofmt breaks their bodies.
+func IsMultiline(expr dst.Expr) bool {
+	if expr == nil || !mayBreakLines(expr) {
+		return false
+	}
+
+	// Print the expression as the value of a declaration, which starts on the
+	// first line after the package clause
+	file := &dst.File{
+		Name: dst.NewIdent("p"),
+		Decls: []dst.Decl{&dst.GenDecl{
+			Tok: token.VAR,
+			Specs: []dst.Spec{&dst.ValueSpec{
+				Names:  []*dst.Ident{dst.NewIdent("_")},
+				Values: []dst.Expr{dst.Clone(expr).(dst.Expr)},
+			}},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := decorator.Fprint(&buf, file); err != nil {
+		return true
+	}
+
+	return strings.Count(strings.TrimSpace(buf.String()), "\n") > 2
+}
+
+// mayBreakLines reports whether some part of a node starts or ends on a new
+// line, or is a function literal, whose body gofmt may break over lines.
+func mayBreakLines(node dst.Node) bool {
+	found := false
+
+	dst.Inspect(node, func(n dst.Node) bool {
+		if n == nil || found {
+			return false
+		}
+
+		_, funcLit := n.(*dst.FuncLit)
+		decs := n.Decorations()
+		found = funcLit || decs.Before != dst.None || decs.After != dst.None
+
+		return !found
+	})
+
+	return found
+}
@@ -2,9 +2,11 @@ package ast
 
 import (
 	"go/token"
+	"slices"
 	"testing"
 
 	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
 )
 
 func TestIsExported(t *testing.T) {
@@ -132,6 +134,36 @@ func TestExtractReceiverTypeName(t *testing.T) {
 	}
 }
 
+func TestIsPointerReceiver(t *testing.T) {
+	tests := []struct {
+		name     string
+		recv     *dst.FieldList
+		expected bool
+	}{
+		{"nil receiver", nil, false},
+		{"value receiver", &dst.FieldList{List: []*dst.Field{{Type: &dst.Ident{Name: "Foo"}}}}, false},
+		{"pointer receiver", &dst.FieldList{List: []*dst.Field{{Type: &dst.StarExpr{X: &dst.Ident{Name: "Foo"}}}}}, true},
+		{
+			name: "generic pointer receiver",
+			recv: &dst.FieldList{List: []*dst.Field{{Type: &dst.StarExpr{
+				X: &dst.IndexExpr{X: &dst.Ident{Name: "List"}, Index: &dst.Ident{Name: "T"}},
+			}}}},
+			expected: true,
+		},
+		{"parenthesized pointer receiver", &dst.FieldList{List: []*dst.Field{{Type: &dst.ParenExpr{
+			X: &dst.StarExpr{X: &dst.Ident{Name: "Foo"}},
+		}}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPointerReceiver(tt.recv); got != tt.expected {
+				t.Errorf("IsPointerReceiver() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestContainsIota(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -182,6 +214,145 @@ func TestContainsIota(t *testing.T) {
 	}
 }
 
+func TestIsGeneric(t *testing.T) {
+	file, err := decorator.Parse(`package p
+
+func Map[T, U any](in []T, f func(T) U) []U { return nil }
+
+func Plain() {}
+
+type List[T any] struct{}
+
+type Plain struct{}
+
+type (
+	Head struct{}
+	Tail[T any] struct{}
+)
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		generic  bool
+		expected bool
+	}{
+		{"generic func", IsGenericFunc(file.Decls[0].(*dst.FuncDecl)), true},
+		{"plain func", IsGenericFunc(file.Decls[1].(*dst.FuncDecl)), false},
+		{"generic type", IsGenericType(file.Decls[2].(*dst.GenDecl)), true},
+		{"plain type", IsGenericType(file.Decls[3].(*dst.GenDecl)), false},
+		{"block led by a plain type", IsGenericType(file.Decls[4].(*dst.GenDecl)), false},
+	}
+
+	for _, tt := range tests {
+		if tt.generic != tt.expected {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.generic, tt.expected)
+		}
+	}
+}
+
+func TestCgo(t *testing.T) {
+	file, err := decorator.Parse(`package p
+
+import "fmt"
+
+// #include <stdio.h>
+import "C"
+
+// Add adds.
+//
+//export Add
+func Add(a, b C.int) C.int { return a + b }
+
+// Exported is not exported to C.
+func Exported() { fmt.Println() }
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if IsCgoImport(file.Decls[0]) {
+		t.Error(`IsCgoImport(import "fmt") = true, want false`)
+	}
+	if !IsCgoImport(file.Decls[1]) {
+		t.Error(`IsCgoImport(import "C") = false, want true`)
+	}
+	if IsCgoImport(file.Decls[2]) {
+		t.Error("IsCgoImport(func) = true, want false")
+	}
+
+	if got := CgoExportName(file.Decls[2].(*dst.FuncDecl)); got != "Add" {
+		t.Errorf("CgoExportName(Add) = %q, want %q", got, "Add")
+	}
+	if got := CgoExportName(file.Decls[3].(*dst.FuncDecl)); got != "" {
+		t.Errorf("CgoExportName(Exported) = %q, want empty", got)
+	}
+}
+
+func TestIsDirective(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected bool
+	}{
+		{"//go:generate stringer -type=Status", true},
+		{"//go:embed static", true},
+		{"//nolint:errcheck", true},
+		{"//export Add", true},
+		{"//line foo.go:10", true},
+		{"// go:generate with a space is prose", false},
+		{"// Note: prose with a colon", false},
+		{"//TODO: fix", false},
+		{"/* go:embed */", false},
+		{"//", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsDirective(tt.line); got != tt.expected {
+			t.Errorf("IsDirective(%q) = %v, want %v", tt.line, got, tt.expected)
+		}
+	}
+}
+
+func TestBlankLines(t *testing.T) {
+	tests := []struct {
+		decs      dst.Decorations
+		dropped   dst.Decorations
+		withBlank dst.Decorations
+	}{
+		{
+			decs:      dst.Decorations{"// line", "\n"},
+			dropped:   dst.Decorations{"// line"},
+			withBlank: dst.Decorations{"// line", "\n", "\n"},
+		},
+		{
+			decs:      dst.Decorations{"/* block */", "\n"},
+			dropped:   dst.Decorations{"/* block */", "\n"},
+			withBlank: dst.Decorations{"/* block */", "\n", "\n"},
+		},
+		{
+			decs:      dst.Decorations{"/* block */", "\n", "\n", "// line"},
+			dropped:   dst.Decorations{"/* block */", "\n", "// line"},
+			withBlank: dst.Decorations{"/* block */", "\n", "\n", "// line", "\n"},
+		},
+		{
+			decs:      dst.Decorations{"/* block */"},
+			dropped:   dst.Decorations{"/* block */"},
+			withBlank: dst.Decorations{"/* block */", "\n", "\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := DropBlankLines(tt.decs); !slices.Equal(got, tt.dropped) {
+			t.Errorf("DropBlankLines(%q) = %q, want %q", tt.decs, got, tt.dropped)
+		}
+		if got := WithBlankLine(slices.Clone(tt.decs)); !slices.Equal(got, tt.withBlank) {
+			t.Errorf("WithBlankLine(%q) = %q, want %q", tt.decs, got, tt.withBlank)
+		}
+	}
+}
+
 func TestIsIotaBlock(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -246,6 +417,92 @@ func TestIsIotaBlock(t *testing.T) {
 	}
 }
 
+func TestIsTypedBlock(t *testing.T) {
+	typedSpec := func(name, typeName string) *dst.ValueSpec {
+		return &dst.ValueSpec{
+			Names:  []*dst.Ident{{Name: name}},
+			Type:   &dst.Ident{Name: typeName},
+			Values: []dst.Expr{&dst.BasicLit{Kind: token.STRING, Value: `"` + name + `"`}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		decl     *dst.GenDecl
+		expected bool
+	}{
+		{
+			name: "all specs share a type",
+			decl: &dst.GenDecl{
+				Tok:    token.CONST,
+				Lparen: true,
+				Specs:  []dst.Spec{typedSpec("OK", "Status"), typedSpec("Err", "Status")},
+			},
+			expected: true,
+		},
+		{
+			name: "mixed types",
+			decl: &dst.GenDecl{
+				Tok:    token.CONST,
+				Lparen: true,
+				Specs:  []dst.Spec{typedSpec("OK", "Status"), typedSpec("Low", "Level")},
+			},
+			expected: false,
+		},
+		{
+			name: "untyped spec",
+			decl: &dst.GenDecl{
+				Tok:    token.CONST,
+				Lparen: true,
+				Specs: []dst.Spec{
+					typedSpec("OK", "Status"),
+					&dst.ValueSpec{Names: []*dst.Ident{{Name: "Err"}}},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "qualified type",
+			decl: &dst.GenDecl{
+				Tok:    token.CONST,
+				Lparen: true,
+				Specs: []dst.Spec{
+					&dst.ValueSpec{
+						Names: []*dst.Ident{{Name: "Timeout"}},
+						Type:  &dst.SelectorExpr{X: &dst.Ident{Name: "time"}, Sel: &dst.Ident{Name: "Duration"}},
+					},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "not parenthesized",
+			decl: &dst.GenDecl{
+				Tok:   token.CONST,
+				Specs: []dst.Spec{typedSpec("OK", "Status")},
+			},
+			expected: false,
+		},
+		{
+			name: "var block",
+			decl: &dst.GenDecl{
+				Tok:    token.VAR,
+				Lparen: true,
+				Specs:  []dst.Spec{typedSpec("OK", "Status")},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTypedBlock(tt.decl); got != tt.expected {
+				t.Errorf("IsTypedBlock() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestExtractEnumType(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -297,3 +554,95 @@ func TestExtractEnumType(t *testing.T) {
 		})
 	}
 }
+
+func parseExpr(t *testing.T, src string) dst.Expr {
+	t.Helper()
+
+	file, err := decorator.Parse("package p\nvar _ = " + src + "\n")
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", src, err)
+	}
+
+	return file.Decls[0].(*dst.GenDecl).Specs[0].(*dst.ValueSpec).Values[0]
+}
+
+func TestReferencedNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected []string
+		excluded []string
+	}{
+		{"binary", `host + ":" + port`, []string{"host", "port"}, nil},
+		{"selector", `flag.String(name, x.field, "")`, []string{"flag", "name", "x"}, []string{"String", "field"}},
+		{"composite", `[]string{first, second}`, []string{"first", "second", "string"}, nil},
+		{"struct type", `struct{ config Config; logger }`, []string{"Config", "logger"}, []string{"config"}},
+		{"func literal", `func(in input) (out output) { return }`, []string{"input", "output"}, []string{"in", "out"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names := ReferencedNames(parseExpr(t, tt.src))
+			for _, name := range tt.expected {
+				if !names[name] {
+					t.Errorf("ReferencedNames(%q) missing %q: %v", tt.src, name, names)
+				}
+			}
+			for _, name := range tt.excluded {
+				if names[name] {
+					t.Errorf("ReferencedNames(%q) should not include %q", tt.src, name)
+				}
+			}
+		})
+	}
+}
+
+func TestHasSideEffects(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected bool
+	}{
+		{"literal", `"localhost"`, false},
+		{"builtin call", `len(names)`, false},
+		{"conversion", `[]byte(string(data))`, false},
+		{"function call", `flag.String("addr", "", "")`, true},
+		{"local call", `compute()`, true},
+		{"nested call", `len(load())`, true},
+		{"channel receive", `<-ready`, true},
+		{"func literal", `func() int { return compute() }`, false},
+		{"called func literal", `func() int { return 1 }()`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasSideEffects(parseExpr(t, tt.src)); got != tt.expected {
+				t.Errorf("HasSideEffects(%q) = %v, want %v", tt.src, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsMultiline(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected bool
+	}{
+		{"literal", `"localhost"`, false},
+		{"one-line func literal", `func() int { return 1 }`, false},
+		{"func literal", "func() int {\n\treturn 1\n}", true},
+		{"one-line composite", `[]int{1, 2}`, false},
+		{"composite", "[]int{\n\t1, 2,\n}", true},
+		{"wrapped binary", "\"a\" +\n\t\"b\"", true},
+		{"break gofmt drops", "*\n\tptr", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsMultiline(parseExpr(t, tt.src)); got != tt.expected {
+				t.Errorf("IsMultiline(%q) = %v, want %v", tt.src, got, tt.expected)
+			}
+		})
+	}
+}
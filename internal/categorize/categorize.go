@@ -3,6 +3,9 @@ package categorize
 
 import (
 	"go/token"
+	"maps"
+	"path"
+	"slices"
 	"sort"
 	"strings"
 
@@ -27,14 +30,115 @@ type CategorizedDecls struct {
 	UnexportedVars   []*dst.ValueSpec
 	UnexportedTypes  []*TypeGroup
 	UnexportedFuncs  []*dst.FuncDecl
-	Uncategorized    []dst.Decl
+	TestMain         *dst.FuncDecl
+	Tests            []*dst.FuncDecl
+	Benchmarks       []*dst.FuncDecl
+	FuzzTests        []*dst.FuncDecl
+	Examples         []*dst.FuncDecl
+	TestHelpers      []*dst.FuncDecl
+	// Generic functions and types, split out of the sections above only when
+	// Config enables the matching section
+	ExportedGenericFuncs   []*dst.FuncDecl
+	UnexportedGenericFuncs []*dst.FuncDecl
+	ExportedGenericTypes   []*TypeGroup
+	UnexportedGenericTypes []*TypeGroup
+	// Functions exported to C with //export, split out only when Config enables it
+	CgoExports []*dst.FuncDecl
+	// Const blocks whose values depend on the position of their specs (iota,
+	// implicitly repeated values) that aren't enums. They are never merged or
+	// reordered internally and follow the merged consts of their section.
+	ExportedConstBlocks   []*dst.GenDecl
+	UnexportedConstBlocks []*dst.GenDecl
+	Uncategorized         []dst.Decl
 }
 
-// EnumGroup pairs an enum type with its iota const block and associated methods.
+// Config controls optional categorization behavior.
+type Config struct {
+	// AssertionsWithTypes groups interface compliance assertions
+	// (var _ I = (*T)(nil)) with the type group of T.
+	AssertionsWithTypes bool
+	// AssertionsWithEnums groups interface compliance assertions with the enum group of T.
+	AssertionsWithEnums bool
+	// IotaEnums treats typed iota const blocks as enums.
+	IotaEnums bool
+	// TypedBlockEnums treats const blocks whose specs all share a file-local
+	// named type as enums, even without iota. Typed consts of such a type in
+	// other blocks join its enum too, gathered into a block of their own.
+	TypedBlockEnums bool
+	// ConstructorPrefixes lists the function name prefixes that mark constructors.
+	ConstructorPrefixes []string
+	// ConstructorAnyReturn matches constructors on any return position, not just the first.
+	ConstructorAnyReturn bool
+	// ConstructorReturnsInterface attaches factories returning a file-local interface
+	// to the interface. When false, as in a zero Config, they attach to the
+	// concrete type named by the function (NewOsRunEnv -> osRunEnv) instead.
+	// DefaultConfig sets it.
+	ConstructorReturnsInterface bool
+	// OptionsWithTypes groups functional options (funcs returning a file-local
+	// option type) with that type.
+	OptionsWithTypes bool
+	// OptionTypePatterns lists glob patterns (path.Match syntax) of type names to
+	// treat as option types in addition to file-local func types.
+	OptionTypePatterns []string
+	// InterfaceMethods groups the methods of each type by the interfaces it
+	// implements (file-local ones and those in InterfaceMethodSets), in the
+	// interface's method order.
+	InterfaceMethods bool
+	// InterfaceMethodSets maps external interfaces (e.g. "io.Reader") to their
+	// method names in declaration order.
+	InterfaceMethodSets map[string][]string
+	// TestFile sorts TestMain, tests, benchmarks, fuzz tests, examples and
+	// test helpers into their own sections instead of the func sections.
+	TestFile bool
+	// TestTargets maps declaration keys of the code under test (see DeclarationKeys)
+	// to their position. When set, tests, benchmarks and fuzz tests are ordered by
+	// the position of the declaration their name targets instead of alphabetically.
+	TestTargets map[string]int
+	// ExamplesByTarget orders examples by the identifier they document (package
+	// example first, then each identifier followed by its methods) instead of
+	// alphabetically.
+	ExamplesByTarget bool
+	// ExampleTargets maps declaration keys of the package to their position. When
+	// set, examples follow the position of the identifier they document.
+	ExampleTargets map[string]int
+	// ConstsByDependency orders const specs so each follows the specs its value
+	// refers to, breaking ties alphabetically.
+	ConstsByDependency bool
+	// VarsByDependency orders var specs so each follows the specs its value
+	// refers to, breaking ties alphabetically.
+	VarsByDependency bool
+	// TypesByDependency orders type groups so each follows the types it refers to,
+	// keeping mutually dependent types together and alphabetical.
+	TypesByDependency bool
+	// FuncsStepdown orders standalone functions so callers come before callees.
+	FuncsStepdown bool
+	// ExportedGenericFuncs, UnexportedGenericFuncs, ExportedGenericTypes and
+	// UnexportedGenericTypes move functions and type groups with type parameters
+	// out of the regular func and type sections into their own.
+	ExportedGenericFuncs   bool
+	UnexportedGenericFuncs bool
+	ExportedGenericTypes   bool
+	UnexportedGenericTypes bool
+	// CgoExports moves functions carrying an //export directive into their own
+	// section. Only meaningful for cgo files (see IsCgoFile).
+	CgoExports bool
+
+	// KeepTypeBlocks keeps grouped type declarations (type ( A ...; B ... ))
+	// intact, as the typedef of their first type. Otherwise each type gets its
+	// own declaration.
+	KeepTypeBlocks bool
+	// SplitTypeBlockDoc carries the doc comment of a split type block to the
+	// declaration of its first type, instead of dropping it.
+	SplitTypeBlockDoc bool
+}
+
+// EnumGroup pairs an enum type with its iota const blocks and associated methods.
+// ConstDecls holds every const block for the type, in source order.
 type EnumGroup struct {
 	TypeName          string
 	TypeDecl          *dst.GenDecl
-	ConstDecl         *dst.GenDecl
+	ConstDecls        []*dst.GenDecl
+	Assertions        []*dst.ValueSpec
 	ExportedMethods   []*dst.FuncDecl
 	UnexportedMethods []*dst.FuncDecl
 }
@@ -43,38 +147,338 @@ type EnumGroup struct {
 type TypeGroup struct {
 	TypeName          string
 	TypeDecl          *dst.GenDecl
+	Assertions        []*dst.ValueSpec
 	Constructors      []*dst.FuncDecl
+	Options           []*dst.FuncDecl
+	InterfaceMethods  []*InterfaceMethodSet
 	ExportedMethods   []*dst.FuncDecl
 	UnexportedMethods []*dst.FuncDecl
 }
 
-// getFirstReturnTypeName extracts the type name from the first return value of a function.
-// Returns empty string if the function has no return values or the type can't be determined.
-// Handles both direct types (TypeName) and pointer types (*TypeName).
-func getFirstReturnTypeName(fn *dst.FuncDecl) string {
-	if fn.Type.Results == nil || len(fn.Type.Results.List) == 0 {
+// InterfaceMethodSet holds the methods a type implements for one interface, in
+// the interface's method order.
+type InterfaceMethodSet struct {
+	Interface string
+	Methods   []*dst.FuncDecl
+}
+
+// Spacing selects where blank lines go between emitted declarations. The zero
+// value separates every declaration and merged specs sit on consecutive lines.
+type Spacing struct {
+	// CompactGroups drops the blank line before the undocumented declarations
+	// of a type or enum group after its first one that have the same kind as
+	// the declaration above, e.g. between a constructor and a method. gofmt
+	// always separates a typedef from its constructor.
+	CompactGroups bool
+	// SpaceMultilineSpecs puts a blank line before and after merged const and
+	// var specs whose values span several lines.
+	SpaceMultilineSpecs bool
+}
+
+// CompactGroup drops the blank line before each undocumented declaration of a
+// group after the first, when spacing asks for compact groups.
+func CompactGroup(decls []dst.Decl, spacing Spacing) {
+	if !spacing.CompactGroups {
+		return
+	}
+
+	for i := 1; i < len(decls); i++ {
+		JoinDecls(decls[i-1], decls[i])
+	}
+}
+
+// JoinDecls drops the blank line between two adjacent declarations, unless the
+// second is documented or they are of different kinds (a type and a func, say),
+// which gofmt always separates.
+func JoinDecls(prev, next dst.Decl) {
+	decs := next.Decorations()
+	if len(decs.Start) > 0 || declToken(prev) != declToken(next) {
+		return
+	}
+
+	decs.Before = dst.NewLine
+	prev.Decorations().After = dst.NewLine
+}
+
+// declToken returns the keyword of a declaration, as gofmt compares them.
+func declToken(decl dst.Decl) token.Token {
+	if genDecl, ok := decl.(*dst.GenDecl); ok {
+		return genDecl.Tok
+	}
+
+	return token.FUNC
+}
+
+// DefaultConfig returns the default categorization configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		AssertionsWithTypes:         true,
+		AssertionsWithEnums:         true,
+		IotaEnums:                   true,
+		ConstructorPrefixes:         []string{"New", "Must"},
+		ConstructorReturnsInterface: true,
+	}
+}
+
+// assertionTarget returns the file-local type an interface compliance assertion
+// converts to, or empty string if the spec is not such an assertion.
+// Recognized forms: var _ I = (*T)(nil), T{}, &T{}, new(T) and T(x).
+func assertionTarget(vspec *dst.ValueSpec, localTypes map[string]bool) string {
+	if len(vspec.Names) != 1 || vspec.Names[0].Name != "_" || len(vspec.Values) != 1 {
+		return ""
+	}
+
+	var typeExpr dst.Expr
+
+	switch value := vspec.Values[0].(type) {
+	case *dst.CallExpr:
+		if ident, ok := value.Fun.(*dst.Ident); ok && ident.Name == "new" && len(value.Args) == 1 {
+			typeExpr = value.Args[0]
+		} else {
+			typeExpr = value.Fun
+		}
+	case *dst.CompositeLit:
+		typeExpr = value.Type
+	case *dst.UnaryExpr:
+		if lit, ok := value.X.(*dst.CompositeLit); ok && value.Op == token.AND {
+			typeExpr = lit.Type
+		}
+	}
+
+	name := localTypeName(typeExpr)
+	if !localTypes[name] {
+		return ""
+	}
+
+	return name
+}
+
+// localTypeName extracts a type name from an expression that may refer to a
+// type declared in this package. Qualified (pkg.T) types yield empty string.
+func localTypeName(expr dst.Expr) string {
+	switch typeExpr := expr.(type) {
+	case *dst.Ident:
+		return typeExpr.Name
+	case *dst.ParenExpr:
+		return localTypeName(typeExpr.X)
+	case *dst.StarExpr:
+		return localTypeName(typeExpr.X)
+	case *dst.IndexExpr:
+		return localTypeName(typeExpr.X)
+	case *dst.IndexListExpr:
+		return localTypeName(typeExpr.X)
+	}
+
+	return ""
+}
+
+// returnTypeNames extracts the type names of a function's return values, one entry
+// per result position. Pointer and generic instantiations (*T, T[K], *T[K, V]) yield
+// T; qualified or unnamed types yield empty string.
+func returnTypeNames(fn *dst.FuncDecl) []string {
+	if fn.Type.Results == nil {
+		return nil
+	}
+
+	var names []string
+
+	for _, field := range fn.Type.Results.List {
+		name := localTypeName(field.Type)
+		// A field like (a, b *T) declares several results of the same type
+		for range max(len(field.Names), 1) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// constructorPrefix returns the configured constructor prefix the function name
+// starts with, or empty string if it has none.
+func constructorPrefix(funcName string, prefixes []string) string {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(funcName, prefix) {
+			return prefix
+		}
+	}
+
+	return ""
+}
+
+// constructorTarget returns the name of the type a constructor candidate belongs to,
+// or empty string if it isn't a constructor under cfg.
+//
+// Matching rules:
+//  1. The function name must start with one of cfg.ConstructorPrefixes
+//  2. The first return (or any return, with cfg.ConstructorAnyReturn) must be a
+//     known type, possibly as a pointer or generic instantiation
+//  3. Interface returns attach to the interface, unless cfg.ConstructorReturnsInterface
+//     is off, in which case the name suffix picks the concrete type (longest match wins)
+func constructorTarget(
+	fn *dst.FuncDecl,
+	cfg *Config,
+	typeGroups map[string]*TypeGroup,
+	interfaceTypes map[string]bool,
+) string {
+	prefix := constructorPrefix(fn.Name.Name, cfg.ConstructorPrefixes)
+	if prefix == "" {
+		return ""
+	}
+
+	for i, returnType := range returnTypeNames(fn) {
+		if i > 0 && !cfg.ConstructorAnyReturn {
+			break
+		}
+
+		if typeGroups[returnType] == nil {
+			continue
+		}
+
+		if interfaceTypes[returnType] && !cfg.ConstructorReturnsInterface {
+			return concreteTypeForName(strings.TrimPrefix(fn.Name.Name, prefix), typeGroups, interfaceTypes)
+		}
+
+		return returnType
+	}
+
+	return ""
+}
+
+// optionTarget returns the option type a functional option builds, or empty string.
+// Option types are file-local func types (type Option func(*Server)) and file-local
+// types whose name matches one of cfg.OptionTypePatterns.
+func optionTarget(fn *dst.FuncDecl, cfg *Config, localTypes, funcTypes map[string]bool) string {
+	returnTypes := returnTypeNames(fn)
+	if len(returnTypes) == 0 || !localTypes[returnTypes[0]] {
+		return ""
+	}
+
+	typeName := returnTypes[0]
+	if funcTypes[typeName] {
+		return typeName
+	}
+
+	for _, pattern := range cfg.OptionTypePatterns {
+		if matched, err := path.Match(pattern, typeName); err == nil && matched {
+			return typeName
+		}
+	}
+
+	return ""
+}
+
+// concreteTypeForName finds the longest non-interface type name that the given
+// constructor name suffix ends with, ignoring case (NewOsRunEnv -> osRunEnv).
+func concreteTypeForName(suffix string, typeGroups map[string]*TypeGroup, interfaceTypes map[string]bool) string {
+	lowerSuffix := strings.ToLower(suffix)
+	best := ""
+
+	for typeName := range typeGroups {
+		if interfaceTypes[typeName] || !strings.HasSuffix(lowerSuffix, strings.ToLower(typeName)) {
+			continue
+		}
+
+		if len(typeName) > len(best) || (len(typeName) == len(best) && typeName < best) {
+			best = typeName
+		}
+	}
+
+	return best
+}
+
+// testingParamType returns the testing type a parameter type refers to
+// (T, B, F, M or TB for *testing.T, ..., testing.TB), or empty string.
+func testingParamType(expr dst.Expr) string {
+	if star, ok := expr.(*dst.StarExpr); ok {
+		expr = star.X
+	}
+
+	sel, ok := expr.(*dst.SelectorExpr)
+	if !ok {
+		return ""
+	}
+
+	if pkg, ok := sel.X.(*dst.Ident); !ok || pkg.Name != "testing" {
+		return ""
+	}
+
+	return sel.Sel.Name
+}
+
+// hasTestPrefix reports whether name is prefix followed by nothing or by a
+// character that isn't a lowercase letter, mirroring the go test naming rule
+// (TestFoo and Test_foo match, Testing does not).
+func hasTestPrefix(name, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+
+	if len(name) == len(prefix) {
+		return true
+	}
+
+	next := name[len(prefix)]
+
+	return next < 'a' || next > 'z'
+}
+
+// testSection returns the test section a function belongs to ("test_main",
+// "tests", "benchmarks", "fuzz_tests" or "examples"), or empty string if it
+// isn't a test entry point. Matching uses the signatures go test requires.
+func testSection(fn *dst.FuncDecl) string {
+	if fn.Recv != nil || fn.Type.TypeParams != nil {
 		return ""
 	}
 
-	firstResult := fn.Type.Results.List[0].Type
+	name := fn.Name.Name
+	params := fn.Type.Params.List
+	hasResults := fn.Type.Results != nil && len(fn.Type.Results.List) > 0
 
-	// Handle pointer types: *TypeName
-	if starExpr, ok := firstResult.(*dst.StarExpr); ok {
-		if ident, ok := starExpr.X.(*dst.Ident); ok {
-			return ident.Name
+	if hasTestPrefix(name, "Example") {
+		if len(params) == 0 && !hasResults {
+			return "examples"
 		}
+
+		return ""
+	}
+
+	if hasResults || len(params) != 1 || len(params[0].Names) > 1 {
 		return ""
 	}
 
-	// Handle direct types: TypeName
-	if ident, ok := firstResult.(*dst.Ident); ok {
-		return ident.Name
+	switch paramType := testingParamType(params[0].Type); {
+	case name == "TestMain" && paramType == "M":
+		return "test_main"
+	case hasTestPrefix(name, "Test") && paramType == "T":
+		return "tests"
+	case hasTestPrefix(name, "Benchmark") && paramType == "B":
+		return "benchmarks"
+	case hasTestPrefix(name, "Fuzz") && paramType == "F":
+		return "fuzz_tests"
 	}
 
 	return ""
 }
 
-// CategorizeDeclarations organizes all declarations by category.
+// isTestHelper reports whether a function takes a *testing.T, *testing.B,
+// *testing.F or testing.TB parameter without being a test entry point.
+func isTestHelper(fn *dst.FuncDecl) bool {
+	for _, param := range fn.Type.Params.List {
+		switch testingParamType(param.Type) {
+		case "T", "B", "F", "TB":
+			return true
+		}
+	}
+
+	return false
+}
+
+// CategorizeDeclarations organizes all declarations by category using the default config.
+func CategorizeDeclarations(file *dst.File) *CategorizedDecls {
+	return CategorizeDeclarationsWithConfig(file, DefaultConfig())
+}
+
+// CategorizeDeclarationsWithConfig organizes all declarations by category.
 //
 // The algorithm uses four passes to properly handle Go's declaration patterns:
 //
@@ -85,41 +489,94 @@ func getFirstReturnTypeName(fn *dst.FuncDecl) string {
 // Pass 2 - Categorize declarations: The main categorization pass. Processes each
 // declaration and assigns it to the appropriate category (imports, consts, vars,
 // types, funcs). Methods are associated with their receiver types, and constructors
-// are matched to types using a longest-suffix match algorithm.
+// are matched to types by return type. Functional options are matched to their
+// option type when enabled in cfg. Interface compliance
+// assertions (var _ I = (*T)(nil)) are associated with T when enabled in cfg.
+// In test files (cfg.TestFile), test entry points and helpers taking a testing
+// handle go to the test sections.
 //
-// Pass 3 - Pair enums with types: Enum const blocks (iota patterns) are identified
+// Pass 3 - Pair enums with types: Enum const blocks (iota patterns, and typed const
+// blocks when enabled in cfg) are identified
 // in Pass 2, but their type definitions may appear separately. This pass pairs enum
-// const blocks with their type definitions and transfers methods from TypeGroup to
-// EnumGroup, then removes the type from the regular types list.
+// const blocks with their type definitions (all blocks for one type share a single
+// EnumGroup, chained in source order) and transfers methods from TypeGroup to
+// EnumGroup, then removes the type from the regular types list. Before pairing,
+// assertions whose group doesn't take them are returned to the unexported vars.
 //
 // Pass 4 - Add method-only types: Handles files that contain methods for types
 // defined elsewhere. TypeGroups that have methods but no TypeDecl are added to
 // the appropriate (exported/unexported) types list so they're not lost.
 //
 //nolint:gocognit,gocyclo,cyclop,funlen,maintidx // Complex by nature - handles all Go declaration types
-func CategorizeDeclarations(file *dst.File) *CategorizedDecls {
+func CategorizeDeclarationsWithConfig(file *dst.File, cfg *Config) *CategorizedDecls {
 	cat := &CategorizedDecls{}
 
 	// Maps for grouping types with their methods and constructors
 	typeGroups := make(map[string]*TypeGroup)
 	// Track which type names are enums (have iota const blocks)
 	enumTypes := make(map[string]bool)
+	// Enum groups by type name, so multiple const blocks share one group
+	enumGroups := make(map[string]*EnumGroup)
+	// Typed consts of each enum found outside its blocks, and the block
+	// gathering them
+	enumSpecs := make(map[string][]*dst.ValueSpec)
+	enumSpecBlocks := make(map[string]*dst.GenDecl)
+	// Track which type names are declared in this file
+	localTypes := make(map[string]bool)
+	// Track which types have had their declaration categorized
+	listedTypes := make(map[string]bool)
+	// Track which of those types are interfaces or func types
+	interfaceTypes := make(map[string]bool)
+	funcTypes := make(map[string]bool)
+	// Groups of the other types of each kept type block, by the group holding it
+	blockFollowers := make(map[*TypeGroup][]*TypeGroup)
 
 	// Pass 1: Collect all type names
 	// We need to know all types before categorizing so we can:
 	// - Match constructors (NewFoo) to their types (Foo)
 	// - Associate methods with their receiver types
+	// Types declared in other files are known by their methods, so constructors
+	// find them wherever the methods appear.
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*dst.FuncDecl); ok && fn.Recv != nil {
+			if typeName := ast.ExtractReceiverTypeName(fn.Recv); typeGroups[typeName] == nil {
+				typeGroups[typeName] = &TypeGroup{TypeName: typeName}
+			}
+		}
+	}
 	for _, decl := range file.Decls {
 		if genDecl, ok := decl.(*dst.GenDecl); ok && genDecl.Tok == token.TYPE {
 			for _, spec := range genDecl.Specs {
 				if tspec, ok := spec.(*dst.TypeSpec); ok {
 					typeName := tspec.Name.Name
 					typeGroups[typeName] = &TypeGroup{TypeName: typeName}
+					localTypes[typeName] = true
+					switch tspec.Type.(type) {
+					case *dst.InterfaceType:
+						interfaceTypes[typeName] = true
+					case *dst.FuncType:
+						funcTypes[typeName] = true
+					}
 				}
 			}
 		}
 	}
 
+	// The enum group of a type, created on first use
+	enumGroupFor := func(typeName string) *EnumGroup {
+		if enumGroups[typeName] == nil {
+			enumGroups[typeName] = &EnumGroup{TypeName: typeName}
+			if ast.IsExported(typeName) {
+				cat.ExportedEnums = append(cat.ExportedEnums, enumGroups[typeName])
+			} else {
+				cat.UnexportedEnums = append(cat.UnexportedEnums, enumGroups[typeName])
+			}
+			enumTypes[typeName] = true
+		}
+
+		return enumGroups[typeName]
+	}
+
 	// Pass 2: Categorize all declarations
 	for _, decl := range file.Decls {
 		switch genDecl := decl.(type) {
@@ -129,33 +586,46 @@ func CategorizeDeclarations(file *dst.File) *CategorizedDecls {
 			case token.IMPORT:
 				cat.Imports = append(cat.Imports, genDecl)
 			case token.CONST:
-				// Check if this is a typed iota block (enum pattern)
+				// Check if this is a typed iota block or typed const block (enum pattern)
 				typeName := ""
-				if ast.IsIotaBlock(genDecl) {
+				if cfg.IotaEnums && ast.IsIotaBlock(genDecl) {
 					typeName = ast.ExtractEnumType(genDecl)
 				}
+				if typeName == "" && cfg.TypedBlockEnums && ast.IsTypedBlock(genDecl) {
+					if name := ast.ExtractEnumType(genDecl); localTypes[name] {
+						typeName = name
+					}
+				}
 
-				if typeName != "" { //nolint:nestif // Categorization logic requires nested conditions
+				if typeName != "" {
 					// Typed iota block = enum
-					exported := ast.IsExported(typeName)
-
-					if exported {
-						cat.ExportedEnums = append(cat.ExportedEnums, &EnumGroup{
-							TypeName:  typeName,
-							ConstDecl: genDecl,
-						})
+					// Further blocks for the same type chain onto its existing group
+					group := enumGroupFor(typeName)
+					group.ConstDecls = append(group.ConstDecls, genDecl)
+				} else if isPositionalConstBlock(genDecl) {
+					// Moving specs would change their values
+					if ast.IsExported(firstSpecName(genDecl)) {
+						cat.ExportedConstBlocks = append(cat.ExportedConstBlocks, genDecl)
 					} else {
-						cat.UnexportedEnums = append(cat.UnexportedEnums, &EnumGroup{
-							TypeName:  typeName,
-							ConstDecl: genDecl,
-						})
+						cat.UnexportedConstBlocks = append(cat.UnexportedConstBlocks, genDecl)
 					}
-
-					enumTypes[typeName] = true
 				} else {
 					// Regular const - extract specs for merging
+					liftSpecDecorations(genDecl)
 					for _, spec := range genDecl.Specs {
-						if vspec, ok := spec.(*dst.ValueSpec); ok {
+						if vspec, ok := spec.(*dst.ValueSpec); ok { //nolint:nestif // Categorization logic requires nested conditions
+							// A typed const joins its enum, or merging would build a
+							// typed block that the next run takes for one
+							if name := ast.SpecTypeName(vspec); cfg.TypedBlockEnums && localTypes[name] {
+								if enumSpecBlocks[name] == nil {
+									enumSpecBlocks[name] = newGenDeclTemplate(token.CONST)
+									group := enumGroupFor(name)
+									group.ConstDecls = append(group.ConstDecls, enumSpecBlocks[name])
+								}
+								enumSpecs[name] = append(enumSpecs[name], vspec)
+								continue
+							}
+
 							if len(vspec.Names) > 0 {
 								exported := ast.IsExported(vspec.Names[0].Name)
 								if exported {
@@ -169,8 +639,16 @@ func CategorizeDeclarations(file *dst.File) *CategorizedDecls {
 				}
 			case token.VAR:
 				// Extract specs for merging
+				liftSpecDecorations(genDecl)
 				for _, spec := range genDecl.Specs {
 					if vspec, ok := spec.(*dst.ValueSpec); ok {
+						if cfg.AssertionsWithTypes || cfg.AssertionsWithEnums {
+							if target := assertionTarget(vspec, localTypes); target != "" {
+								typeGroups[target].Assertions = append(typeGroups[target].Assertions, vspec)
+								continue
+							}
+						}
+
 						if len(vspec.Names) > 0 {
 							exported := ast.IsExported(vspec.Names[0].Name)
 							if exported {
@@ -183,7 +661,8 @@ func CategorizeDeclarations(file *dst.File) *CategorizedDecls {
 				}
 			case token.TYPE:
 				// Extract type name
-				for _, spec := range genDecl.Specs {
+				var blockGroup *TypeGroup
+				for i, spec := range genDecl.Specs {
 					if tspec, ok := spec.(*dst.TypeSpec); ok { //nolint:nestif // Type extraction requires nested type assertions
 						typeName := tspec.Name.Name
 						exported := ast.IsExported(typeName)
@@ -195,20 +674,36 @@ func CategorizeDeclarations(file *dst.File) *CategorizedDecls {
 							}
 						}
 
+						// A redeclared type does not compile, but both declarations are
+						// kept: the later one gets a group of its own
+						group := typeGroups[typeName]
+						if listedTypes[typeName] {
+							group = &TypeGroup{TypeName: typeName}
+						}
+						listedTypes[typeName] = true
+
 						// Create individual GenDecl for this type to avoid duplicate
 						// node issues when a grouped type declaration is split across
-						// multiple TypeGroups.
-						typeGroups[typeName].TypeDecl = &dst.GenDecl{
-							Tok:   token.TYPE,
-							Specs: []dst.Spec{tspec},
+						// multiple TypeGroups. A lone type keeps its own declaration,
+						// with its doc comment and directives. A kept block goes to the
+						// group of its first type; the other types keep their groups for
+						// their constructors and methods.
+						switch {
+						case len(genDecl.Specs) == 1 || (cfg.KeepTypeBlocks && i == 0):
+							group.TypeDecl = genDecl
+							blockGroup = group
+						case !cfg.KeepTypeBlocks:
+							group.TypeDecl = splitTypeSpec(genDecl, tspec, cfg.SplitTypeBlockDoc && i == 0)
+						default:
+							blockFollowers[blockGroup] = append(blockFollowers[blockGroup], group)
 						}
 
 						// Add to categorized list if not an enum type
-						if !enumTypes[typeName] {
+						if !enumTypes[typeName] || group != typeGroups[typeName] {
 							if exported {
-								cat.ExportedTypes = append(cat.ExportedTypes, typeGroups[typeName])
+								cat.ExportedTypes = append(cat.ExportedTypes, group)
 							} else {
-								cat.UnexportedTypes = append(cat.UnexportedTypes, typeGroups[typeName])
+								cat.UnexportedTypes = append(cat.UnexportedTypes, group)
 							}
 						}
 					}
@@ -242,20 +737,56 @@ func CategorizeDeclarations(file *dst.File) *CategorizedDecls {
 				funcName := genDecl.Name.Name
 				exported := ast.IsExported(funcName)
 
-				// Check if it's a constructor (New* or Must* prefix, matched by return type)
-				// Constructor matching algorithm (aligned with funcorder):
-				// 1. Function must have New* or Must* prefix
+				// Test entry points get their own sections in test files
+				if cfg.TestFile {
+					switch testSection(genDecl) {
+					case "test_main":
+						cat.TestMain = genDecl
+						continue
+					case "tests":
+						cat.Tests = append(cat.Tests, genDecl)
+						continue
+					case "benchmarks":
+						cat.Benchmarks = append(cat.Benchmarks, genDecl)
+						continue
+					case "fuzz_tests":
+						cat.FuzzTests = append(cat.FuzzTests, genDecl)
+						continue
+					case "examples":
+						cat.Examples = append(cat.Examples, genDecl)
+						continue
+					}
+				}
+
+				// C entry points stay together, whatever their name or return type
+				if cfg.CgoExports && ast.CgoExportName(genDecl) != "" {
+					cat.CgoExports = append(cat.CgoExports, genDecl)
+					continue
+				}
+
+				// Check if it's a constructor (configured prefix, matched by return type)
+				// Constructor matching algorithm (aligned with funcorder by default):
+				// 1. Function must have a constructor prefix (New*, Must*)
 				// 2. Match by return type: first return must be TypeName or *TypeName defined in this file
-				isConstructorCandidate := strings.HasPrefix(funcName, "New") || strings.HasPrefix(funcName, "Must")
-				if isConstructorCandidate {
-					if returnType := getFirstReturnTypeName(genDecl); returnType != "" {
-						if typeGroups[returnType] != nil {
-							typeGroups[returnType].Constructors = append(typeGroups[returnType].Constructors, genDecl)
-							continue
-						}
+				if target := constructorTarget(genDecl, cfg, typeGroups, interfaceTypes); target != "" {
+					typeGroups[target].Constructors = append(typeGroups[target].Constructors, genDecl)
+					continue
+				}
+
+				// Functional options (WithTimeout() Option) gather with their option type
+				if cfg.OptionsWithTypes {
+					if target := optionTarget(genDecl, cfg, localTypes, funcTypes); target != "" {
+						typeGroups[target].Options = append(typeGroups[target].Options, genDecl)
+						continue
 					}
 				}
 
+				// Functions taking a testing handle are helpers in test files
+				if cfg.TestFile && isTestHelper(genDecl) {
+					cat.TestHelpers = append(cat.TestHelpers, genDecl)
+					continue
+				}
+
 				// Not a constructor, add to standalone functions
 				if exported {
 					cat.ExportedFuncs = append(cat.ExportedFuncs, genDecl)
@@ -266,6 +797,32 @@ func CategorizeDeclarations(file *dst.File) *CategorizedDecls {
 		}
 	}
 
+	// Return grouped declarations their group won't emit: assertions the group
+	// kind doesn't take, and constructors/options of enum types (EnumGroup has
+	// no slot for them)
+	for name, tg := range typeGroups {
+		if (enumTypes[name] && !cfg.AssertionsWithEnums) || (!enumTypes[name] && !cfg.AssertionsWithTypes) {
+			cat.UnexportedVars = append(cat.UnexportedVars, tg.Assertions...)
+			tg.Assertions = nil
+		}
+
+		if enumTypes[name] {
+			for _, fn := range slices.Concat(tg.Constructors, tg.Options) {
+				if ast.IsExported(fn.Name.Name) {
+					cat.ExportedFuncs = append(cat.ExportedFuncs, fn)
+				} else {
+					cat.UnexportedFuncs = append(cat.UnexportedFuncs, fn)
+				}
+			}
+			tg.Constructors = nil
+			tg.Options = nil
+		}
+	}
+
+	for name, block := range enumSpecBlocks {
+		block.Specs = spaceSpecs(enumSpecs[name], false)
+	}
+
 	// Pass 3: Pair enum types with their const blocks
 	// Enum const blocks were added to cat.ExportedEnums/UnexportedEnums in Pass 2,
 	// but the corresponding type definitions went into typeGroups. This pass:
@@ -275,6 +832,7 @@ func CategorizeDeclarations(file *dst.File) *CategorizedDecls {
 	for _, enumGroup := range cat.ExportedEnums {
 		if typeGroups[enumGroup.TypeName] != nil {
 			enumGroup.TypeDecl = typeGroups[enumGroup.TypeName].TypeDecl
+			enumGroup.Assertions = typeGroups[enumGroup.TypeName].Assertions
 			// Transfer methods from TypeGroup to EnumGroup
 			enumGroup.ExportedMethods = typeGroups[enumGroup.TypeName].ExportedMethods
 			enumGroup.UnexportedMethods = typeGroups[enumGroup.TypeName].UnexportedMethods
@@ -291,6 +849,7 @@ func CategorizeDeclarations(file *dst.File) *CategorizedDecls {
 	for _, enumGroup := range cat.UnexportedEnums {
 		if typeGroups[enumGroup.TypeName] != nil {
 			enumGroup.TypeDecl = typeGroups[enumGroup.TypeName].TypeDecl
+			enumGroup.Assertions = typeGroups[enumGroup.TypeName].Assertions
 			// Transfer methods from TypeGroup to EnumGroup
 			enumGroup.ExportedMethods = typeGroups[enumGroup.TypeName].ExportedMethods
 			enumGroup.UnexportedMethods = typeGroups[enumGroup.TypeName].UnexportedMethods
@@ -310,7 +869,9 @@ func CategorizeDeclarations(file *dst.File) *CategorizedDecls {
 	// but no TypeDecl (nil). We add them to the appropriate types list so they're included
 	// in the output. Without this pass, methods in such files would be silently dropped.
 	for _, tg := range typeGroups {
-		if tg.TypeDecl == nil && (len(tg.ExportedMethods) > 0 || len(tg.UnexportedMethods) > 0) {
+		// Types declared in a kept block are already listed, and enums of external
+		// types took their methods in Pass 3
+		if tg.TypeDecl == nil && !localTypes[tg.TypeName] && !enumTypes[tg.TypeName] && (len(tg.ExportedMethods) > 0 || len(tg.UnexportedMethods) > 0) {
 			if ast.IsExported(tg.TypeName) {
 				cat.ExportedTypes = append(cat.ExportedTypes, tg)
 			} else {
@@ -321,6 +882,45 @@ func CategorizeDeclarations(file *dst.File) *CategorizedDecls {
 
 	// Sort everything
 	SortCategorized(cat)
+	SplitGenerics(cat, cfg)
+
+	if cfg.InterfaceMethods {
+		GroupInterfaceMethods(cat, file, cfg.InterfaceMethodSets)
+	}
+
+	if cfg.TestTargets != nil {
+		SortTestsByTarget(cat, cfg.TestTargets)
+	}
+
+	if cfg.ConstsByDependency {
+		SortSpecsByDependency(cat.ExportedConsts)
+		SortSpecsByDependency(cat.UnexportedConsts)
+	}
+
+	if cfg.VarsByDependency {
+		SortSpecsByDependency(cat.ExportedVars)
+		SortSpecsByDependency(cat.UnexportedVars)
+	}
+
+	if cfg.TypesByDependency {
+		SortTypesByDependency(cat.ExportedTypes)
+		SortTypesByDependency(cat.UnexportedTypes)
+		SortTypesByDependency(cat.ExportedGenericTypes)
+		SortTypesByDependency(cat.UnexportedGenericTypes)
+	}
+
+	// The other types of a kept block follow it, whichever way types are sorted
+	for _, groups := range [][]*TypeGroup{cat.ExportedTypes, cat.UnexportedTypes, cat.ExportedGenericTypes, cat.UnexportedGenericTypes} {
+		followTypeBlocks(groups, blockFollowers)
+	}
+
+	if cfg.FuncsStepdown {
+		SortFuncsStepdown(cat)
+	}
+
+	if cfg.ExamplesByTarget {
+		SortExamplesByTarget(cat.Examples, cfg.ExampleTargets)
+	}
 
 	return cat
 }
@@ -419,6 +1019,11 @@ func SortCategorized(cat *CategorizedDecls) {
 	sort.Slice(cat.UnexportedConsts, func(i, j int) bool {
 		return cat.UnexportedConsts[i].Names[0].Name < cat.UnexportedConsts[j].Names[0].Name
 	})
+	for _, blocks := range [][]*dst.GenDecl{cat.ExportedConstBlocks, cat.UnexportedConstBlocks} {
+		sort.SliceStable(blocks, func(i, j int) bool {
+			return firstSpecName(blocks[i]) < firstSpecName(blocks[j])
+		})
+	}
 
 	// Sort var specs by name
 	sort.Slice(cat.ExportedVars, func(i, j int) bool {
@@ -466,6 +1071,9 @@ func SortCategorized(cat *CategorizedDecls) {
 		sort.Slice(typeGrp.Constructors, func(i, j int) bool {
 			return typeGrp.Constructors[i].Name.Name < typeGrp.Constructors[j].Name.Name
 		})
+		sort.Slice(typeGrp.Options, func(i, j int) bool {
+			return typeGrp.Options[i].Name.Name < typeGrp.Options[j].Name.Name
+		})
 		sort.Slice(typeGrp.ExportedMethods, func(i, j int) bool {
 			return typeGrp.ExportedMethods[i].Name.Name < typeGrp.ExportedMethods[j].Name.Name
 		})
@@ -478,6 +1086,9 @@ func SortCategorized(cat *CategorizedDecls) {
 		sort.Slice(typeGrp.Constructors, func(i, j int) bool {
 			return typeGrp.Constructors[i].Name.Name < typeGrp.Constructors[j].Name.Name
 		})
+		sort.Slice(typeGrp.Options, func(i, j int) bool {
+			return typeGrp.Options[i].Name.Name < typeGrp.Options[j].Name.Name
+		})
 		sort.Slice(typeGrp.ExportedMethods, func(i, j int) bool {
 			return typeGrp.ExportedMethods[i].Name.Name < typeGrp.ExportedMethods[j].Name.Name
 		})
@@ -493,18 +1104,27 @@ func SortCategorized(cat *CategorizedDecls) {
 	sort.Slice(cat.UnexportedFuncs, func(i, j int) bool {
 		return cat.UnexportedFuncs[i].Name.Name < cat.UnexportedFuncs[j].Name.Name
 	})
+
+	// Sort test functions and C entry points by name
+	for _, funcs := range [][]*dst.FuncDecl{cat.Tests, cat.Benchmarks, cat.FuzzTests, cat.Examples, cat.TestHelpers, cat.CgoExports} {
+		sort.Slice(funcs, func(i, j int) bool {
+			return funcs[i].Name.Name < funcs[j].Name.Name
+		})
+	}
 }
 
 // CollectUncategorized moves declarations from excluded sections to uncategorized.
 //
 //nolint:funlen,gocognit,cyclop // Section handling is inherently repetitive
-func CollectUncategorized(cat *CategorizedDecls, includedSections map[string]bool) {
-	if !includedSections["exported_consts"] && len(cat.ExportedConsts) > 0 {
-		cat.Uncategorized = append(cat.Uncategorized, MergeConstSpecs(cat.ExportedConsts, "Exported constants."))
+func CollectUncategorized(cat *CategorizedDecls, includedSections map[string]bool, spacing Spacing) {
+	if !includedSections["exported_consts"] {
+		cat.Uncategorized = append(cat.Uncategorized,
+			ConstDecls(cat.ExportedConsts, cat.ExportedConstBlocks, "Exported constants.", spacing.SpaceMultilineSpecs)...)
 		cat.ExportedConsts = nil
+		cat.ExportedConstBlocks = nil
 	}
 	if !includedSections["exported_vars"] && len(cat.ExportedVars) > 0 {
-		cat.Uncategorized = append(cat.Uncategorized, MergeVarSpecs(cat.ExportedVars, "Exported variables."))
+		cat.Uncategorized = append(cat.Uncategorized, VarDecls(cat.ExportedVars, "Exported variables.", spacing.SpaceMultilineSpecs)...)
 		cat.ExportedVars = nil
 	}
 	if !includedSections["exported_funcs"] {
@@ -514,12 +1134,14 @@ func CollectUncategorized(cat *CategorizedDecls, includedSections map[string]boo
 		}
 		cat.ExportedFuncs = nil
 	}
-	if !includedSections["unexported_consts"] && len(cat.UnexportedConsts) > 0 {
-		cat.Uncategorized = append(cat.Uncategorized, MergeConstSpecs(cat.UnexportedConsts, "unexported constants."))
+	if !includedSections["unexported_consts"] {
+		cat.Uncategorized = append(cat.Uncategorized,
+			ConstDecls(cat.UnexportedConsts, cat.UnexportedConstBlocks, "unexported constants.", spacing.SpaceMultilineSpecs)...)
 		cat.UnexportedConsts = nil
+		cat.UnexportedConstBlocks = nil
 	}
 	if !includedSections["unexported_vars"] && len(cat.UnexportedVars) > 0 {
-		cat.Uncategorized = append(cat.Uncategorized, MergeVarSpecs(cat.UnexportedVars, "unexported variables."))
+		cat.Uncategorized = append(cat.Uncategorized, VarDecls(cat.UnexportedVars, "unexported variables.", spacing.SpaceMultilineSpecs)...)
 		cat.UnexportedVars = nil
 	}
 	if !includedSections["unexported_funcs"] {
@@ -529,89 +1151,132 @@ func CollectUncategorized(cat *CategorizedDecls, includedSections map[string]boo
 		}
 		cat.UnexportedFuncs = nil
 	}
-	// Handle types (includes type decl, constructors, methods)
-	if !includedSections["exported_types"] {
-		for _, tg := range cat.ExportedTypes {
-			if tg.TypeDecl != nil {
-				tg.TypeDecl.Decs.Before = dst.EmptyLine
-				cat.Uncategorized = append(cat.Uncategorized, tg.TypeDecl)
-			}
-			for _, ctor := range tg.Constructors {
-				ctor.Decs.Before = dst.EmptyLine
-				cat.Uncategorized = append(cat.Uncategorized, ctor)
-			}
-			for _, m := range tg.ExportedMethods {
-				m.Decs.Before = dst.EmptyLine
-				cat.Uncategorized = append(cat.Uncategorized, m)
-			}
-			for _, m := range tg.UnexportedMethods {
-				m.Decs.Before = dst.EmptyLine
-				cat.Uncategorized = append(cat.Uncategorized, m)
-			}
+	if !includedSections["test_main"] && cat.TestMain != nil {
+		cat.TestMain.Decs.Before = dst.EmptyLine
+		cat.Uncategorized = append(cat.Uncategorized, cat.TestMain)
+		cat.TestMain = nil
+	}
+	for _, section := range []struct {
+		name  string
+		funcs *[]*dst.FuncDecl
+	}{
+		{"tests", &cat.Tests},
+		{"benchmarks", &cat.Benchmarks},
+		{"fuzz_tests", &cat.FuzzTests},
+		{"examples", &cat.Examples},
+		{"test_helpers", &cat.TestHelpers},
+		{"exported_generic_funcs", &cat.ExportedGenericFuncs},
+		{"unexported_generic_funcs", &cat.UnexportedGenericFuncs},
+		{"cgo_exports", &cat.CgoExports},
+	} {
+		if includedSections[section.name] {
+			continue
+		}
+		for _, fn := range *section.funcs {
+			fn.Decs.Before = dst.EmptyLine
+			cat.Uncategorized = append(cat.Uncategorized, fn)
 		}
-		cat.ExportedTypes = nil
+		*section.funcs = nil
 	}
-	if !includedSections["unexported_types"] {
-		for _, tg := range cat.UnexportedTypes {
+	// Handle types (includes type decl, constructors, methods)
+	for _, section := range []struct {
+		name   string
+		groups *[]*TypeGroup
+	}{
+		{"exported_types", &cat.ExportedTypes},
+		{"unexported_types", &cat.UnexportedTypes},
+		{"exported_generic_types", &cat.ExportedGenericTypes},
+		{"unexported_generic_types", &cat.UnexportedGenericTypes},
+	} {
+		if includedSections[section.name] {
+			continue
+		}
+		for _, tg := range *section.groups {
+			var group []dst.Decl
 			if tg.TypeDecl != nil {
 				tg.TypeDecl.Decs.Before = dst.EmptyLine
-				cat.Uncategorized = append(cat.Uncategorized, tg.TypeDecl)
+				group = append(group, tg.TypeDecl)
 			}
-			for _, ctor := range tg.Constructors {
-				ctor.Decs.Before = dst.EmptyLine
-				cat.Uncategorized = append(cat.Uncategorized, ctor)
+			for _, spec := range tg.Assertions {
+				decl := SingleSpecDecl(spec)
+				decl.Decs.Before = dst.EmptyLine
+				group = append(group, decl)
 			}
-			for _, m := range tg.ExportedMethods {
-				m.Decs.Before = dst.EmptyLine
-				cat.Uncategorized = append(cat.Uncategorized, m)
+			for _, fn := range slices.Concat(tg.Constructors, tg.Options) {
+				fn.Decs.Before = dst.EmptyLine
+				group = append(group, fn)
+			}
+			for _, set := range tg.InterfaceMethods {
+				for _, m := range set.Methods {
+					m.Decs.Before = dst.EmptyLine
+					group = append(group, m)
+				}
 			}
-			for _, m := range tg.UnexportedMethods {
+			for _, m := range slices.Concat(tg.ExportedMethods, tg.UnexportedMethods) {
 				m.Decs.Before = dst.EmptyLine
-				cat.Uncategorized = append(cat.Uncategorized, m)
+				group = append(group, m)
 			}
+			CompactGroup(group, spacing)
+			cat.Uncategorized = append(cat.Uncategorized, group...)
 		}
-		cat.UnexportedTypes = nil
+		*section.groups = nil
 	}
 	// Handle enums (includes type decl, iota const, methods)
 	if !includedSections["exported_enums"] {
 		for _, eg := range cat.ExportedEnums {
+			var group []dst.Decl
 			if eg.TypeDecl != nil {
 				eg.TypeDecl.Decs.Before = dst.EmptyLine
-				cat.Uncategorized = append(cat.Uncategorized, eg.TypeDecl)
+				group = append(group, eg.TypeDecl)
+			}
+			for _, spec := range eg.Assertions {
+				decl := SingleSpecDecl(spec)
+				decl.Decs.Before = dst.EmptyLine
+				group = append(group, decl)
 			}
-			if eg.ConstDecl != nil {
-				eg.ConstDecl.Decs.Before = dst.EmptyLine
-				cat.Uncategorized = append(cat.Uncategorized, eg.ConstDecl)
+			for _, constDecl := range eg.ConstDecls {
+				constDecl.Decs.Before = dst.EmptyLine
+				group = append(group, constDecl)
 			}
 			for _, m := range eg.ExportedMethods {
 				m.Decs.Before = dst.EmptyLine
-				cat.Uncategorized = append(cat.Uncategorized, m)
+				group = append(group, m)
 			}
 			for _, m := range eg.UnexportedMethods {
 				m.Decs.Before = dst.EmptyLine
-				cat.Uncategorized = append(cat.Uncategorized, m)
+				group = append(group, m)
 			}
+			CompactGroup(group, spacing)
+			cat.Uncategorized = append(cat.Uncategorized, group...)
 		}
 		cat.ExportedEnums = nil
 	}
 	if !includedSections["unexported_enums"] {
 		for _, eg := range cat.UnexportedEnums {
+			var group []dst.Decl
 			if eg.TypeDecl != nil {
 				eg.TypeDecl.Decs.Before = dst.EmptyLine
-				cat.Uncategorized = append(cat.Uncategorized, eg.TypeDecl)
+				group = append(group, eg.TypeDecl)
 			}
-			if eg.ConstDecl != nil {
-				eg.ConstDecl.Decs.Before = dst.EmptyLine
-				cat.Uncategorized = append(cat.Uncategorized, eg.ConstDecl)
+			for _, spec := range eg.Assertions {
+				decl := SingleSpecDecl(spec)
+				decl.Decs.Before = dst.EmptyLine
+				group = append(group, decl)
+			}
+			for _, constDecl := range eg.ConstDecls {
+				constDecl.Decs.Before = dst.EmptyLine
+				group = append(group, constDecl)
 			}
 			for _, m := range eg.ExportedMethods {
 				m.Decs.Before = dst.EmptyLine
-				cat.Uncategorized = append(cat.Uncategorized, m)
+				group = append(group, m)
 			}
 			for _, m := range eg.UnexportedMethods {
 				m.Decs.Before = dst.EmptyLine
-				cat.Uncategorized = append(cat.Uncategorized, m)
+				group = append(group, m)
 			}
+			CompactGroup(group, spacing)
+			cat.Uncategorized = append(cat.Uncategorized, group...)
 		}
 		cat.UnexportedEnums = nil
 	}
@@ -628,7 +1293,7 @@ func FindExcludedSections(cat *CategorizedDecls, includedSections map[string]boo
 	if !includedSections["init"] && len(cat.Init) > 0 {
 		excluded = append(excluded, "init")
 	}
-	if !includedSections["exported_consts"] && len(cat.ExportedConsts) > 0 {
+	if !includedSections["exported_consts"] && len(cat.ExportedConsts)+len(cat.ExportedConstBlocks) > 0 {
 		excluded = append(excluded, "exported_consts")
 	}
 	if !includedSections["exported_vars"] && len(cat.ExportedVars) > 0 {
@@ -643,7 +1308,7 @@ func FindExcludedSections(cat *CategorizedDecls, includedSections map[string]boo
 	if !includedSections["exported_enums"] && len(cat.ExportedEnums) > 0 {
 		excluded = append(excluded, "exported_enums")
 	}
-	if !includedSections["unexported_consts"] && len(cat.UnexportedConsts) > 0 {
+	if !includedSections["unexported_consts"] && len(cat.UnexportedConsts)+len(cat.UnexportedConstBlocks) > 0 {
 		excluded = append(excluded, "unexported_consts")
 	}
 	if !includedSections["unexported_vars"] && len(cat.UnexportedVars) > 0 {
@@ -658,6 +1323,39 @@ func FindExcludedSections(cat *CategorizedDecls, includedSections map[string]boo
 	if !includedSections["unexported_enums"] && len(cat.UnexportedEnums) > 0 {
 		excluded = append(excluded, "unexported_enums")
 	}
+	if !includedSections["test_main"] && cat.TestMain != nil {
+		excluded = append(excluded, "test_main")
+	}
+	if !includedSections["tests"] && len(cat.Tests) > 0 {
+		excluded = append(excluded, "tests")
+	}
+	if !includedSections["benchmarks"] && len(cat.Benchmarks) > 0 {
+		excluded = append(excluded, "benchmarks")
+	}
+	if !includedSections["fuzz_tests"] && len(cat.FuzzTests) > 0 {
+		excluded = append(excluded, "fuzz_tests")
+	}
+	if !includedSections["examples"] && len(cat.Examples) > 0 {
+		excluded = append(excluded, "examples")
+	}
+	if !includedSections["test_helpers"] && len(cat.TestHelpers) > 0 {
+		excluded = append(excluded, "test_helpers")
+	}
+	if !includedSections["exported_generic_funcs"] && len(cat.ExportedGenericFuncs) > 0 {
+		excluded = append(excluded, "exported_generic_funcs")
+	}
+	if !includedSections["unexported_generic_funcs"] && len(cat.UnexportedGenericFuncs) > 0 {
+		excluded = append(excluded, "unexported_generic_funcs")
+	}
+	if !includedSections["exported_generic_types"] && len(cat.ExportedGenericTypes) > 0 {
+		excluded = append(excluded, "exported_generic_types")
+	}
+	if !includedSections["unexported_generic_types"] && len(cat.UnexportedGenericTypes) > 0 {
+		excluded = append(excluded, "unexported_generic_types")
+	}
+	if !includedSections["cgo_exports"] && len(cat.CgoExports) > 0 {
+		excluded = append(excluded, "cgo_exports")
+	}
 	if !includedSections["uncategorized"] && len(cat.Uncategorized) > 0 {
 		excluded = append(excluded, "uncategorized")
 	}
@@ -665,66 +1363,1264 @@ func FindExcludedSections(cat *CategorizedDecls, includedSections map[string]boo
 	return excluded
 }
 
-// newGenDeclTemplate creates a GenDecl by parsing a template to ensure proper
-// internal state for DST's restorer. This is necessary because directly
-// constructing a GenDecl struct loses internal tracking that DST uses for
-// correct trailing comment placement on the last spec.
-func newGenDeclTemplate(tok token.Token) *dst.GenDecl {
-	var keyword string
-	if tok == token.CONST {
-		keyword = "const"
-	} else {
-		keyword = "var"
-	}
+// DeclarationKeys returns the keys a top-level declaration defines: functions and
+// types by name, methods as Type.Method, and every const and var name.
+func DeclarationKeys(decl dst.Decl) []string {
+	var keys []string
 
-	src := "package p\n" + keyword + " (\n\tx int\n)\n"
-	dec := decorator.NewDecorator(token.NewFileSet())
-	file, _ := dec.Parse(src)
+	switch d := decl.(type) {
+	case *dst.FuncDecl:
+		if d.Recv != nil {
+			return []string{ast.ExtractReceiverTypeName(d.Recv) + "." + d.Name.Name}
+		}
 
-	for _, decl := range file.Decls {
-		if genDecl, ok := decl.(*dst.GenDecl); ok && genDecl.Tok == tok {
-			return dst.Clone(genDecl).(*dst.GenDecl)
+		return []string{d.Name.Name}
+	case *dst.GenDecl:
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *dst.TypeSpec:
+				keys = append(keys, s.Name.Name)
+			case *dst.ValueSpec:
+				for _, name := range s.Names {
+					keys = append(keys, name.Name)
+				}
+			}
 		}
 	}
 
-	// Fallback (should not happen)
-	return &dst.GenDecl{Tok: tok, Lparen: true}
+	return keys
 }
 
-// MergeConstSpecs creates a single const block from multiple specs.
-func MergeConstSpecs(specs []*dst.ValueSpec, comment string) *dst.GenDecl {
-	dstSpecs := make([]dst.Spec, 0, len(specs))
+// SortTestsByTarget orders tests, benchmarks and fuzz tests by the position of the
+// declaration each one targets, following the TestFunc / TestType_Method naming
+// convention. Functions without a known target keep their relative order after
+// the targeted ones.
+func SortTestsByTarget(cat *CategorizedDecls, positions map[string]int) {
+	for _, section := range []struct {
+		prefix string
+		funcs  []*dst.FuncDecl
+	}{
+		{"Test", cat.Tests},
+		{"Benchmark", cat.Benchmarks},
+		{"Fuzz", cat.FuzzTests},
+	} {
+		targets := make(map[*dst.FuncDecl]int, len(section.funcs))
+		for _, fn := range section.funcs {
+			targets[fn] = testTargetPosition(strings.TrimPrefix(fn.Name.Name, section.prefix), positions)
+		}
 
-	for _, spec := range specs {
-		spec.Decs.Before = dst.NewLine
-		spec.Decs.After = dst.NewLine
-		dstSpecs = append(dstSpecs, spec)
+		sort.SliceStable(section.funcs, func(i, j int) bool {
+			return targets[section.funcs[i]] < targets[section.funcs[j]]
+		})
 	}
-
-	decl := newGenDeclTemplate(token.CONST)
-	decl.Specs = dstSpecs
-	decl.Decs.Before = dst.EmptyLine
-	decl.Decs.Start = nil
-	decl.Decs.Start.Append("// " + comment)
-
-	return decl
 }
 
-// MergeVarSpecs creates a single var block from multiple specs.
-func MergeVarSpecs(specs []*dst.ValueSpec, comment string) *dst.GenDecl {
-	dstSpecs := make([]dst.Spec, 0, len(specs))
+// SortSpecsByDependency orders value specs topologically: a spec whose value refers
+// to names declared by other specs in the list comes after them. Among specs that
+// are ready at the same time the alphabetically first goes next. When none is
+// ready (a cycle), the alphabetically first remaining spec goes next.
+func SortSpecsByDependency(specs []*dst.ValueSpec) {
+	sort.SliceStable(specs, func(i, j int) bool {
+		return specs[i].Names[0].Name < specs[j].Names[0].Name
+	})
 
-	for _, spec := range specs {
-		spec.Decs.Before = dst.NewLine
-		spec.Decs.After = dst.NewLine
-		dstSpecs = append(dstSpecs, spec)
+	// A redeclared name can't be told apart from its other declarations, so
+	// references to it are left unresolved
+	declaredBy := make(map[string]int)
+	redeclared := make(map[string]bool)
+	for i, spec := range specs {
+		for _, name := range spec.Names {
+			if _, ok := declaredBy[name.Name]; ok {
+				redeclared[name.Name] = true
+			}
+			declaredBy[name.Name] = i
+		}
+	}
+	for name := range redeclared {
+		delete(declaredBy, name)
+	}
+
+	deps := make([][]int, len(specs))
+	for i, spec := range specs {
+		for _, value := range spec.Values {
+			for name := range ast.ReferencedNames(value) {
+				if j, ok := declaredBy[name]; ok && name != "_" && j != i {
+					deps[i] = append(deps[i], j)
+				}
+			}
+		}
+	}
+
+	placed := make([]bool, len(specs))
+	ordered := make([]*dst.ValueSpec, 0, len(specs))
+
+	for len(ordered) < len(specs) {
+		next := -1
+		for i := range specs {
+			if placed[i] {
+				continue
+			}
+			if next == -1 {
+				next = i
+			}
+			if !slices.ContainsFunc(deps[i], func(j int) bool { return !placed[j] }) {
+				next = i
+				break
+			}
+		}
+
+		placed[next] = true
+		ordered = append(ordered, specs[next])
+	}
+
+	copy(specs, ordered)
+}
+
+// SplitGenerics moves generic functions and type groups (those declaring type
+// parameters) into the generic sections Config enables, keeping their order.
+// Constructors, options and methods stay with their type group.
+func SplitGenerics(cat *CategorizedDecls, cfg *Config) {
+	isGenericGroup := func(tg *TypeGroup) bool {
+		return tg.TypeDecl != nil && ast.IsGenericType(tg.TypeDecl)
+	}
+
+	if cfg.ExportedGenericFuncs {
+		cat.ExportedFuncs, cat.ExportedGenericFuncs = partition(cat.ExportedFuncs, ast.IsGenericFunc)
+	}
+	if cfg.UnexportedGenericFuncs {
+		cat.UnexportedFuncs, cat.UnexportedGenericFuncs = partition(cat.UnexportedFuncs, ast.IsGenericFunc)
+	}
+	if cfg.ExportedGenericTypes {
+		cat.ExportedTypes, cat.ExportedGenericTypes = partition(cat.ExportedTypes, isGenericGroup)
+	}
+	if cfg.UnexportedGenericTypes {
+		cat.UnexportedTypes, cat.UnexportedGenericTypes = partition(cat.UnexportedTypes, isGenericGroup)
+	}
+}
+
+// partition splits items into those that don't match and those that do, keeping order.
+func partition[T any](items []T, match func(T) bool) (rest, matched []T) {
+	for _, item := range items {
+		if match(item) {
+			matched = append(matched, item)
+		} else {
+			rest = append(rest, item)
+		}
+	}
+
+	return rest, matched
+}
+
+// GroupInterfaceMethods moves the methods of each type group that implement an
+// interface into InterfaceMethods, one set per interface in the interface's
+// method order. Candidates are the interfaces declared in the file, matched by
+// method name and signature, plus the external method sets, matched by method
+// name alone (as are methods embedded from them). Larger interfaces claim their
+// methods first (ties by name), so a type implementing heap.Interface gets one
+// heap.Interface set rather than a sort.Interface set plus Push and Pop.
+// Interfaces with type elements (constraints) or embedded interfaces that
+// can't be resolved are skipped.
+func GroupInterfaceMethods(cat *CategorizedDecls, file *dst.File, external map[string][]string) {
+	candidates := localInterfaces(file, external)
+	for _, name := range slices.Sorted(maps.Keys(external)) {
+		if len(external[name]) > 0 {
+			candidates = append(candidates, interfaceMethodNames{Interface: name, Methods: external[name]})
+		}
+	}
+
+	slices.SortStableFunc(candidates, func(a, b interfaceMethodNames) int {
+		if len(a.Methods) != len(b.Methods) {
+			return len(b.Methods) - len(a.Methods)
+		}
+
+		return strings.Compare(a.Interface, b.Interface)
+	})
+
+	for _, tg := range slices.Concat(cat.ExportedTypes, cat.ExportedGenericTypes, cat.UnexportedTypes, cat.UnexportedGenericTypes) {
+		methods := make(map[string]*dst.FuncDecl)
+		for _, m := range slices.Concat(tg.ExportedMethods, tg.UnexportedMethods) {
+			methods[m.Name.Name] = m
+		}
+
+		claimed := make(map[*dst.FuncDecl]bool)
+		for _, iface := range candidates {
+			implemented := !slices.ContainsFunc(iface.Methods, func(name string) bool {
+				return methods[name] == nil || !iface.matches(methods[name])
+			})
+			if !implemented {
+				continue
+			}
+
+			set := &InterfaceMethodSet{Interface: iface.Interface}
+			for _, name := range iface.Methods {
+				if m := methods[name]; !claimed[m] {
+					claimed[m] = true
+					set.Methods = append(set.Methods, m)
+				}
+			}
+			if len(set.Methods) > 0 {
+				tg.InterfaceMethods = append(tg.InterfaceMethods, set)
+			}
+		}
+
+		tg.ExportedMethods = slices.DeleteFunc(tg.ExportedMethods, func(m *dst.FuncDecl) bool { return claimed[m] })
+		tg.UnexportedMethods = slices.DeleteFunc(tg.UnexportedMethods, func(m *dst.FuncDecl) bool { return claimed[m] })
+	}
+}
+
+// interfaceMethodNames is an interface's method set by name, in declaration order.
+type interfaceMethodNames struct {
+	Interface string
+	Methods   []string
+	// Signatures of the methods declared in the file; the others match by name
+	Signatures map[string]*dst.FuncType
+	// TypeParams are the type parameters of the interface and the interfaces it
+	// embeds, which match any type
+	TypeParams map[string]bool
+}
+
+// matches reports whether a method declaration has the signature of the
+// interface method of the same name, when the interface declares one.
+func (iface interfaceMethodNames) matches(method *dst.FuncDecl) bool {
+	sig, ok := iface.Signatures[method.Name.Name]
+	if !ok {
+		return true
+	}
+
+	return sameFieldTypes(sig.Params, method.Type.Params, iface.TypeParams) &&
+		sameFieldTypes(sig.Results, method.Type.Results, iface.TypeParams)
+}
+
+// localInterfaces returns the method sets of the interfaces declared in a file, in
+// source order. Embedded interfaces are expanded in place, from the file or from
+// the external method sets.
+func localInterfaces(file *dst.File, external map[string][]string) []interfaceMethodNames {
+	declared := make(map[string]*dst.TypeSpec)
+
+	var names []string
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*dst.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			tspec, ok := spec.(*dst.TypeSpec)
+			if !ok || tspec.Assign {
+				continue
+			}
+			if _, ok := tspec.Type.(*dst.InterfaceType); ok {
+				declared[tspec.Name.Name] = tspec
+				names = append(names, tspec.Name.Name)
+			}
+		}
+	}
+
+	var expand func(tspec *dst.TypeSpec, set *interfaceMethodNames, visiting map[*dst.TypeSpec]bool) bool
+	expand = func(tspec *dst.TypeSpec, set *interfaceMethodNames, visiting map[*dst.TypeSpec]bool) bool {
+		if visiting[tspec] {
+			return false
+		}
+		visiting[tspec] = true
+		defer delete(visiting, tspec)
+
+		if tspec.TypeParams != nil {
+			for _, field := range tspec.TypeParams.List {
+				for _, name := range field.Names {
+					set.TypeParams[name.Name] = true
+				}
+			}
+		}
+
+		for _, field := range tspec.Type.(*dst.InterfaceType).Methods.List {
+			if funcType, ok := field.Type.(*dst.FuncType); ok {
+				for _, name := range field.Names {
+					if !slices.Contains(set.Methods, name.Name) {
+						set.Methods = append(set.Methods, name.Name)
+						set.Signatures[name.Name] = funcType
+					}
+				}
+				continue
+			}
+
+			// Instantiated generic interfaces (Container[T]) embed the same method names
+			embeddedType := field.Type
+			switch generic := embeddedType.(type) {
+			case *dst.IndexExpr:
+				embeddedType = generic.X
+			case *dst.IndexListExpr:
+				embeddedType = generic.X
+			}
+
+			var embedded []string
+			switch typeExpr := embeddedType.(type) {
+			case *dst.Ident:
+				inner, ok := declared[typeExpr.Name]
+				if !ok {
+					embedded, ok = external[typeExpr.Name]
+					if !ok {
+						return false
+					}
+					break
+				}
+				if !expand(inner, set, visiting) {
+					return false
+				}
+			case *dst.SelectorExpr:
+				pkg, ok := typeExpr.X.(*dst.Ident)
+				if !ok {
+					return false
+				}
+				if embedded, ok = external[pkg.Name+"."+typeExpr.Sel.Name]; !ok {
+					return false
+				}
+			default:
+				// Type elements (~int, A | B) make it a constraint
+				return false
+			}
+
+			for _, name := range embedded {
+				if !slices.Contains(set.Methods, name) {
+					set.Methods = append(set.Methods, name)
+				}
+			}
+		}
+
+		return true
+	}
+
+	var result []interfaceMethodNames
+	for _, name := range names {
+		set := interfaceMethodNames{
+			Interface:  name,
+			Signatures: make(map[string]*dst.FuncType),
+			TypeParams: make(map[string]bool),
+		}
+		if expand(declared[name], &set, make(map[*dst.TypeSpec]bool)) && len(set.Methods) > 0 {
+			result = append(result, set)
+		}
+	}
+
+	return result
+}
+
+// sameFieldTypes reports whether two parameter or result lists have the same
+// types, whatever their names. Names in wildcards match any type.
+func sameFieldTypes(a, b *dst.FieldList, wildcards map[string]bool) bool {
+	typesA, typesB := fieldTypes(a), fieldTypes(b)
+	if len(typesA) != len(typesB) {
+		return false
+	}
+
+	for i := range typesA {
+		if !sameType(typesA[i], typesB[i], wildcards) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fieldTypes returns the type of each entry of a field list, repeating the
+// type of fields that declare several names (a, b int).
+func fieldTypes(list *dst.FieldList) []dst.Expr {
+	if list == nil {
+		return nil
+	}
+
+	var types []dst.Expr
+	for _, field := range list.List {
+		for range max(len(field.Names), 1) {
+			types = append(types, field.Type)
+		}
+	}
+
+	return types
+}
+
+// sameType reports whether two type expressions denote the same type, as far
+// as the syntax tells. Names in wildcards, taken from a, match any type.
+//
+//nolint:cyclop // One case per kind of type expression
+func sameType(a, b dst.Expr, wildcards map[string]bool) bool {
+	if ident, ok := a.(*dst.Ident); ok && wildcards[ident.Name] {
+		return true
+	}
+	if isEmptyInterface(a) && isEmptyInterface(b) {
+		return true
+	}
+
+	switch a := a.(type) {
+	case *dst.Ident:
+		b, ok := b.(*dst.Ident)
+		return ok && a.Name == b.Name
+	case *dst.SelectorExpr:
+		b, ok := b.(*dst.SelectorExpr)
+		return ok && a.Sel.Name == b.Sel.Name && sameType(a.X, b.X, wildcards)
+	case *dst.ParenExpr:
+		return sameType(a.X, b, wildcards)
+	case *dst.StarExpr:
+		b, ok := b.(*dst.StarExpr)
+		return ok && sameType(a.X, b.X, wildcards)
+	case *dst.Ellipsis:
+		b, ok := b.(*dst.Ellipsis)
+		return ok && sameType(a.Elt, b.Elt, wildcards)
+	case *dst.ArrayType:
+		b, ok := b.(*dst.ArrayType)
+		return ok && (a.Len == nil) == (b.Len == nil) &&
+			(a.Len == nil || sameType(a.Len, b.Len, wildcards)) && sameType(a.Elt, b.Elt, wildcards)
+	case *dst.BasicLit:
+		b, ok := b.(*dst.BasicLit)
+		return ok && a.Kind == b.Kind && a.Value == b.Value
+	case *dst.MapType:
+		b, ok := b.(*dst.MapType)
+		return ok && sameType(a.Key, b.Key, wildcards) && sameType(a.Value, b.Value, wildcards)
+	case *dst.ChanType:
+		b, ok := b.(*dst.ChanType)
+		return ok && a.Dir == b.Dir && sameType(a.Value, b.Value, wildcards)
+	case *dst.FuncType:
+		b, ok := b.(*dst.FuncType)
+		return ok && sameFieldTypes(a.Params, b.Params, wildcards) && sameFieldTypes(a.Results, b.Results, wildcards)
+	case *dst.IndexExpr:
+		b, ok := b.(*dst.IndexExpr)
+		return ok && sameType(a.X, b.X, wildcards) && sameType(a.Index, b.Index, wildcards)
+	case *dst.IndexListExpr:
+		b, ok := b.(*dst.IndexListExpr)
+		return ok && sameType(a.X, b.X, wildcards) &&
+			slices.EqualFunc(a.Indices, b.Indices, func(x, y dst.Expr) bool { return sameType(x, y, wildcards) })
+	case *dst.StructType:
+		b, ok := b.(*dst.StructType)
+		return ok && sameFields(a.Fields, b.Fields, wildcards)
+	case *dst.InterfaceType:
+		b, ok := b.(*dst.InterfaceType)
+		return ok && sameFields(a.Methods, b.Methods, wildcards)
+	}
+
+	return false
+}
+
+// sameFields reports whether two struct field or interface method lists have
+// the same names and types.
+func sameFields(a, b *dst.FieldList, wildcards map[string]bool) bool {
+	fieldNames := func(list *dst.FieldList) []string {
+		var names []string
+		for _, field := range list.List {
+			for _, name := range field.Names {
+				names = append(names, name.Name)
+			}
+			if len(field.Names) == 0 {
+				names = append(names, "")
+			}
+		}
+
+		return names
+	}
+
+	return slices.Equal(fieldNames(a), fieldNames(b)) && sameFieldTypes(a, b, wildcards)
+}
+
+// isEmptyInterface reports whether a type expression is any or interface{}.
+func isEmptyInterface(expr dst.Expr) bool {
+	switch expr := expr.(type) {
+	case *dst.Ident:
+		return expr.Name == "any"
+	case *dst.InterfaceType:
+		return len(expr.Methods.List) == 0
+	}
+
+	return false
+}
+
+// SortTypesByDependency orders type groups topologically: a type comes after the
+// types its definition refers to (field and embedded types, element types,
+// method signatures and type parameter constraints). Mutually dependent types
+// form a strongly connected component and stay together in alphabetical order.
+// Among components that are ready at the same time, the one with the
+// alphabetically first type goes next. Groups are expected sorted by name.
+func SortTypesByDependency(groups []*TypeGroup) {
+	// A redeclared type can't be told apart from its other declarations by
+	// name, so references to it are left unresolved
+	index := make(map[string]int, len(groups))
+	redeclared := make(map[string]bool)
+	for i, tg := range groups {
+		if _, ok := index[tg.TypeName]; ok {
+			redeclared[tg.TypeName] = true
+		}
+		index[tg.TypeName] = i
+	}
+	for name := range redeclared {
+		delete(index, name)
+	}
+
+	deps := make([][]int, len(groups))
+	for i, tg := range groups {
+		// Types declared elsewhere (method-only groups) have no definition here,
+		// so they keep their place by receiver name
+		if tg.TypeDecl == nil {
+			continue
+		}
+
+		for _, spec := range tg.TypeDecl.Specs {
+			tspec, ok := spec.(*dst.TypeSpec)
+			if !ok {
+				continue
+			}
+
+			refs := ast.ReferencedNames(tspec.Type)
+			if tspec.TypeParams != nil {
+				for _, param := range tspec.TypeParams.List {
+					maps.Copy(refs, ast.ReferencedNames(param.Type))
+				}
+				// Type parameters shadow package-level types of the same name
+				for _, param := range tspec.TypeParams.List {
+					for _, name := range param.Names {
+						delete(refs, name.Name)
+					}
+				}
+			}
+
+			for name := range refs {
+				if j, ok := index[name]; ok && j != i {
+					deps[i] = append(deps[i], j)
+				}
+			}
+		}
+	}
+
+	components := stronglyConnected(deps)
+
+	componentOf := make([]int, len(groups))
+	for c, members := range components {
+		for _, i := range members {
+			componentOf[i] = c
+		}
+	}
+
+	placed := make([]bool, len(components))
+	ordered := make([]*TypeGroup, 0, len(groups))
+
+	ready := func(c int) bool {
+		for _, i := range components[c] {
+			for _, j := range deps[i] {
+				if componentOf[j] != c && !placed[componentOf[j]] {
+					return false
+				}
+			}
+		}
+
+		return true
+	}
+
+	for range components {
+		// Members are ascending, so the first member is the component's first name
+		next := -1
+		for c := range components {
+			if placed[c] || !ready(c) {
+				continue
+			}
+			if next == -1 || components[c][0] < components[next][0] {
+				next = c
+			}
+		}
+
+		placed[next] = true
+		for _, i := range components[next] {
+			ordered = append(ordered, groups[i])
+		}
+	}
+
+	copy(groups, ordered)
+}
+
+// stronglyConnected returns the strongly connected components of a graph given as
+// adjacency lists, each as ascending node indices (Tarjan's algorithm).
+func stronglyConnected(edges [][]int) [][]int {
+	var (
+		components [][]int
+		stack      []int
+		counter    int
+	)
+
+	order := make([]int, len(edges))
+	low := make([]int, len(edges))
+	onStack := make([]bool, len(edges))
+
+	var visit func(v int)
+	visit = func(v int) {
+		counter++
+		order[v] = counter
+		low[v] = counter
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range edges[v] {
+			if order[w] == 0 {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], order[w])
+			}
+		}
+
+		if low[v] != order[v] {
+			return
+		}
+
+		var component []int
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			component = append(component, w)
+			if w == v {
+				break
+			}
+		}
+		slices.Sort(component)
+		components = append(components, component)
+	}
+
+	for v := range edges {
+		if order[v] == 0 {
+			visit(v)
+		}
 	}
 
-	decl := newGenDeclTemplate(token.VAR)
-	decl.Specs = dstSpecs
+	return components
+}
+
+// SortFuncsStepdown orders the exported and unexported function sections so callers
+// come before callees, following the intra-file call graph depth-first.
+//
+// Every function and method is a potential entry point, taken in reading order:
+// main, init, test functions, enum and type group functions, then exported and
+// unexported functions (each already sorted by name). Entry points no other
+// function calls are walked first; functions only reachable through a cycle are
+// walked afterwards in the same order. Callees are visited in the order their
+// first call appears, so the result is deterministic.
+func SortFuncsStepdown(cat *CategorizedDecls) {
+	var funcs []*dst.FuncDecl
+	if cat.Main != nil {
+		funcs = append(funcs, cat.Main)
+	}
+	funcs = append(funcs, cat.Init...)
+	if cat.TestMain != nil {
+		funcs = append(funcs, cat.TestMain)
+	}
+	funcs = slices.Concat(funcs, cat.Tests, cat.Benchmarks, cat.FuzzTests, cat.Examples)
+	for _, eg := range slices.Concat(cat.ExportedEnums, cat.UnexportedEnums) {
+		funcs = slices.Concat(funcs, eg.ExportedMethods, eg.UnexportedMethods)
+	}
+	for _, tg := range slices.Concat(cat.ExportedTypes, cat.ExportedGenericTypes, cat.UnexportedTypes, cat.UnexportedGenericTypes) {
+		funcs = slices.Concat(funcs, tg.Constructors, tg.Options)
+		for _, set := range tg.InterfaceMethods {
+			funcs = append(funcs, set.Methods...)
+		}
+		funcs = slices.Concat(funcs, tg.ExportedMethods, tg.UnexportedMethods)
+	}
+	funcs = slices.Concat(funcs, cat.CgoExports, cat.ExportedFuncs, cat.ExportedGenericFuncs, cat.TestHelpers,
+		cat.UnexportedFuncs, cat.UnexportedGenericFuncs)
+
+	// Only standalone functions can be resolved by name, and only when declared
+	// once: the order of redeclarations must not decide which one is called
+	byName := make(map[string]*dst.FuncDecl)
+	redeclared := make(map[string]bool)
+	for _, fn := range funcs {
+		if fn.Recv == nil {
+			if byName[fn.Name.Name] != nil {
+				redeclared[fn.Name.Name] = true
+			}
+			byName[fn.Name.Name] = fn
+		}
+	}
+	for name := range redeclared {
+		delete(byName, name)
+	}
+
+	callees := make(map[*dst.FuncDecl][]*dst.FuncDecl)
+	called := make(map[*dst.FuncDecl]bool)
+
+	for _, caller := range funcs {
+		if caller.Body == nil {
+			continue
+		}
+
+		dst.Inspect(caller.Body, func(node dst.Node) bool {
+			call, ok := node.(*dst.CallExpr)
+			if !ok {
+				return true
+			}
+
+			callee := byName[calleeName(call.Fun)]
+			if callee != nil && callee != caller && !slices.Contains(callees[caller], callee) {
+				callees[caller] = append(callees[caller], callee)
+				called[callee] = true
+			}
+
+			return true
+		})
+	}
+
+	index := make(map[*dst.FuncDecl]int)
+
+	var visit func(fn *dst.FuncDecl)
+	visit = func(fn *dst.FuncDecl) {
+		if _, seen := index[fn]; seen {
+			return
+		}
+
+		index[fn] = len(index)
+		for _, callee := range callees[fn] {
+			visit(callee)
+		}
+	}
+
+	for _, fn := range funcs {
+		if !called[fn] {
+			visit(fn)
+		}
+	}
+
+	for _, fn := range funcs {
+		visit(fn)
+	}
+
+	for _, section := range [][]*dst.FuncDecl{
+		cat.ExportedFuncs, cat.ExportedGenericFuncs, cat.UnexportedFuncs, cat.UnexportedGenericFuncs,
+	} {
+		sort.SliceStable(section, func(i, j int) bool {
+			return index[section[i]] < index[section[j]]
+		})
+	}
+}
+
+// calleeName returns the name of a directly called function (f(), f[T]()), or
+// empty string for method calls, calls of function values and the like.
+func calleeName(fun dst.Expr) string {
+	switch expr := fun.(type) {
+	case *dst.Ident:
+		return expr.Name
+	case *dst.ParenExpr:
+		return calleeName(expr.X)
+	case *dst.IndexExpr:
+		return calleeName(expr.X)
+	case *dst.IndexListExpr:
+		return calleeName(expr.X)
+	}
+
+	return ""
+}
+
+// SortExamplesByTarget orders examples by the identifier they document, following
+// the Example, ExampleF, ExampleT, ExampleT_M naming convention: the package
+// example comes first, then each identifier's examples, methods after their
+// type. With positions, identifiers are ordered by their position in the
+// package (unknown ones last); otherwise by name. Examples for the same target
+// are ordered by suffix, unsuffixed first.
+func SortExamplesByTarget(examples []*dst.FuncDecl, positions map[string]int) {
+	type target struct {
+		typeName, method, suffix string
+		position                 int
+	}
+
+	targets := make(map[*dst.FuncDecl]target, len(examples))
+	for _, fn := range examples {
+		typeName, method, suffix := exampleTarget(fn.Name.Name)
+		position := len(positions)
+		if pos, ok := positions[typeName+"."+method]; ok && method != "" {
+			position = pos
+		} else if pos, ok := positions[typeName]; ok {
+			position = pos
+		}
+		targets[fn] = target{typeName, method, suffix, position}
+	}
+
+	sort.SliceStable(examples, func(i, j int) bool {
+		a, b := targets[examples[i]], targets[examples[j]]
+		if (a.typeName == "") != (b.typeName == "") {
+			return a.typeName == ""
+		}
+		if a.position != b.position {
+			return a.position < b.position
+		}
+		if a.typeName != b.typeName {
+			return a.typeName < b.typeName
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+
+		return a.suffix < b.suffix
+	})
+}
+
+// exampleTarget splits an example name into the identifier it documents and
+// its suffix: Example_x -> ("", "", "x"), ExampleT -> ("T", "", ""),
+// ExampleT_M_x -> ("T", "M", "x"). Suffixes start with a lowercase letter.
+func exampleTarget(name string) (typeName, method, suffix string) {
+	rest := strings.TrimPrefix(name, "Example")
+	if rest == "" || rest[0] == '_' {
+		return "", "", strings.TrimPrefix(rest, "_")
+	}
+
+	typeName, rest, _ = strings.Cut(rest, "_")
+	if rest != "" && (rest[0] < 'a' || rest[0] > 'z') {
+		method, rest, _ = strings.Cut(rest, "_")
+	}
+
+	return typeName, method, rest
+}
+
+// testTargetPosition returns the position of the declaration a test name (without
+// its Test/Benchmark/Fuzz prefix) targets. The longest declaration name the test
+// name starts with wins, matching unexported names too (Parse_empty -> parse);
+// a following _Method selects a method of that type (Server_Start -> Server.Start).
+// Names without a target yield len(positions), sorting after all targets.
+func testTargetPosition(name string, positions map[string]int) int {
+	name = strings.TrimPrefix(name, "_")
+
+	target := longestKeyPrefix(name, positions, "")
+	if target == "" {
+		return len(positions)
+	}
+
+	if rest, ok := strings.CutPrefix(name[len(target):], "_"); ok {
+		if method := longestKeyPrefix(rest, positions, target+"."); method != "" {
+			return positions[method]
+		}
+	}
+
+	return positions[target]
+}
+
+// longestKeyPrefix returns the longest key of the form scope+ident such that name
+// starts with ident (ignoring the case of the first letter) at a word boundary,
+// or empty string. Keys of other scopes are ignored.
+func longestKeyPrefix(name string, positions map[string]int, scope string) string {
+	best := ""
+
+	for key := range positions {
+		ident, ok := strings.CutPrefix(key, scope)
+		if !ok || ident == "" || strings.Contains(ident, ".") || len(ident) > len(name) {
+			continue
+		}
+
+		if name[1:len(ident)] != ident[1:] || !strings.EqualFold(name[:1], ident[:1]) {
+			continue
+		}
+
+		if len(name) > len(ident) && name[len(ident)] >= 'a' && name[len(ident)] <= 'z' {
+			continue
+		}
+
+		// Prefer longer names, then an exact-case match, then the smaller key
+		switch {
+		case best == "" || len(key) > len(best):
+			best = key
+		case len(key) < len(best):
+		case (name[0] == ident[0]) != (name[0] == best[len(scope)]):
+			if name[0] == ident[0] {
+				best = key
+			}
+		case key < best:
+			best = key
+		}
+	}
+
+	return best
+}
+
+// IsTestFile reports whether a file holds tests: either its package name ends
+// in _test or it declares a TestMain, test, benchmark or fuzz test function.
+func IsTestFile(file *dst.File) bool {
+	if strings.HasSuffix(file.Name.Name, "_test") {
+		return true
+	}
+
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*dst.FuncDecl); ok {
+			switch testSection(fn) {
+			case "test_main", "tests", "benchmarks", "fuzz_tests":
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// IsCgoFile reports whether a file uses cgo, i.e. imports "C".
+func IsCgoFile(file *dst.File) bool {
+	return slices.ContainsFunc(file.Decls, ast.IsCgoImport)
+}
+
+// newGenDeclTemplate creates a GenDecl by parsing a template to ensure proper
+// internal state for DST's restorer. This is necessary because directly
+// constructing a GenDecl struct loses internal tracking that DST uses for
+// correct trailing comment placement on the last spec.
+func newGenDeclTemplate(tok token.Token) *dst.GenDecl {
+	var keyword string
+	if tok == token.CONST {
+		keyword = "const"
+	} else {
+		keyword = "var"
+	}
+
+	src := "package p\n" + keyword + " (\n\tx int\n)\n"
+	dec := decorator.NewDecorator(token.NewFileSet())
+	file, _ := dec.Parse(src)
+
+	for _, decl := range file.Decls {
+		if genDecl, ok := decl.(*dst.GenDecl); ok && genDecl.Tok == tok {
+			return dst.Clone(genDecl).(*dst.GenDecl)
+		}
+	}
+
+	// Fallback (should not happen)
+	return &dst.GenDecl{Tok: tok, Lparen: true}
+}
+
+// followTypeBlocks moves the groups in followers, the other types of a kept
+// type block, directly after the group holding the block, in block order. A
+// group stays in place when the block's group is in another list (e.g. an
+// unexported type in a block led by an exported one).
+func followTypeBlocks(groups []*TypeGroup, followers map[*TypeGroup][]*TypeGroup) {
+	if len(followers) == 0 {
+		return
+	}
+
+	listed := make(map[*TypeGroup]bool, len(groups))
+	for _, tg := range groups {
+		listed[tg] = true
+	}
+
+	moved := make(map[*TypeGroup]bool)
+	for block, groupsOfBlock := range followers {
+		if !listed[block] {
+			continue
+		}
+		for _, tg := range groupsOfBlock {
+			moved[tg] = listed[tg]
+		}
+	}
+
+	ordered := make([]*TypeGroup, 0, len(groups))
+	for _, tg := range groups {
+		if moved[tg] {
+			continue
+		}
+
+		ordered = append(ordered, tg)
+		for _, follower := range followers[tg] {
+			if moved[follower] {
+				ordered = append(ordered, follower)
+			}
+		}
+	}
+
+	copy(groups, ordered)
+}
+
+// splitTypeSpec creates a standalone declaration for one type of a grouped type
+// declaration. The spec's own comments move before the type keyword, along with
+// the block's doc comment when withBlockDoc is set.
+func splitTypeSpec(block *dst.GenDecl, tspec *dst.TypeSpec, withBlockDoc bool) *dst.GenDecl {
+	decl := &dst.GenDecl{
+		Tok:   token.TYPE,
+		Specs: []dst.Spec{tspec},
+	}
+	if withBlockDoc {
+		decl.Decs.Start = slices.Clone(block.Decs.Start)
+		// Both comments end up in one doc comment, as separate paragraphs
+		if len(tspec.Decs.Start) > 0 && len(decl.Decs.Start) > 0 && decl.Decs.Start[len(decl.Decs.Start)-1] != "\n" {
+			decl.Decs.Start = append(decl.Decs.Start, "//")
+		}
+	}
+	decl.Decs.Start = append(decl.Decs.Start, tspec.Decs.Start...)
+	tspec.Decs.Start = nil
+	tspec.Decs.Before = dst.None
+	tspec.Decs.After = dst.None
+
+	return decl
+}
+
+// liftSpecDecorations moves the doc and trailing comments of a const or var
+// declaration onto its specs, so they survive when specs are merged into blocks.
+// A parenthesized block's doc comment and directives go to its first spec, and
+// the comments after its closing parenthesis to its last.
+func liftSpecDecorations(genDecl *dst.GenDecl) {
+	defer closeTrailingComments(genDecl)
+
+	if genDecl.Lparen {
+		liftFloatingComments(genDecl)
+		liftBlockComments(genDecl)
+		return
+	}
+	if len(genDecl.Specs) != 1 {
+		return
+	}
+
+	vspec, ok := genDecl.Specs[0].(*dst.ValueSpec)
+	if !ok {
+		return
+	}
+
+	vspec.Decs.Start = append(genDecl.Decs.Start, vspec.Decs.Start...)
+	vspec.Decs.End = append(vspec.Decs.End, genDecl.Decs.End...)
+	genDecl.Decs.Start = nil
+	genDecl.Decs.End = nil
+}
+
+// closeTrailingComments removes the blank lines from the comments on the lines
+// below each spec of a declaration. Merged, a spec may be followed by another
+// one right below those comments, which the next run would read as its doc
+// comment, closing the blank line above it.
+func closeTrailingComments(genDecl *dst.GenDecl) {
+	for _, spec := range genDecl.Specs {
+		end := &spec.Decorations().End
+		if first := slices.Index(*end, "\n"); first >= 0 {
+			rest := ast.DropBlankLines((*end)[first+1:])
+			*end = append(slices.Clone((*end)[:first+1]), rest...)
+		}
+	}
+}
+
+// liftFloatingComments moves the comments of a parenthesized block that are
+// separated from its specs by a blank line, above the block or right after its
+// opening parenthesis, onto its first spec.
+func liftFloatingComments(genDecl *dst.GenDecl) {
+	if len(genDecl.Specs) == 0 {
+		return
+	}
+
+	var floating dst.Decorations
+
+	// Above the block: everything up to the last blank line
+	start := genDecl.Decs.Start
+	if split := lastBlankLine(start); split >= 0 {
+		floating = append(floating, start[:split+1]...)
+		genDecl.Decs.Start = slices.Clone(start[split+1:])
+	}
+
+	// After the parenthesis: everything from the first line break; the blank
+	// line below is the first spec's
+	lparen := genDecl.Decs.Lparen
+	if split := slices.Index(lparen, "\n"); split >= 0 && split < len(lparen)-1 {
+		floating = ast.WithBlankLine(append(floating, lparen[split+1:]...))
+		genDecl.Decs.Lparen = slices.Clone(lparen[:split])
+	}
+
+	if len(floating) == 0 {
+		return
+	}
+
+	first := genDecl.Specs[0].Decorations()
+	first.Start = append(floating, first.Start...)
+}
+
+// liftBlockComments moves the comments a parenthesized block keeps after
+// liftFloatingComments onto its specs: the doc comment, directives and any
+// comment after the opening parenthesis onto the first, the comment after the
+// closing parenthesis onto the last. The header of a block merged on an earlier
+// run is dropped, as merging writes it again.
+func liftBlockComments(genDecl *dst.GenDecl) {
+	if len(genDecl.Specs) == 0 {
+		return
+	}
+
+	// The comments around the keyword and parenthesis join the doc comment,
+	// without the line breaks that follow them
+	var doc dst.Decorations
+	for _, line := range genDecl.Decs.Start {
+		if !isMergedBlockHeader(line) {
+			doc = append(doc, line)
+		}
+	}
+	for _, line := range slices.Concat(genDecl.Decs.Tok, genDecl.Decs.Lparen) {
+		if line != "\n" {
+			doc = append(doc, line)
+		}
+	}
+
+	first := genDecl.Specs[0].Decorations()
+	first.Start = append(doc, first.Start...)
+
+	last := genDecl.Specs[len(genDecl.Specs)-1].Decorations()
+	last.End = append(last.End, genDecl.Decs.End...)
+
+	genDecl.Decs.Start = nil
+	genDecl.Decs.Tok = nil
+	genDecl.Decs.Lparen = nil
+	genDecl.Decs.End = nil
+}
+
+// isMergedBlockHeader reports whether a comment line is the header written
+// above a merged const or var block.
+func isMergedBlockHeader(line string) bool {
+	switch line {
+	case "// Exported constants.", "// unexported constants.", "// Exported variables.", "// unexported variables.":
+		return true
+	default:
+		return false
+	}
+}
+
+// lastBlankLine returns the index of the last blank line in decorations, or -1.
+func lastBlankLine(decs dst.Decorations) int {
+	split := -1
+	for i := range decs {
+		if ast.IsBlankLine(decs, i) {
+			split = i
+		}
+	}
+
+	return split
+}
+
+// hasBlankLine reports whether decorations hold a blank line.
+func hasBlankLine(decs dst.Decorations) bool {
+	return lastBlankLine(decs) >= 0
+}
+
+// hasEmbedDirective reports whether a var spec carries a //go:embed directive.
+func hasEmbedDirective(spec *dst.ValueSpec) bool {
+	return slices.ContainsFunc(spec.Decs.Start, func(line string) bool {
+		return strings.HasPrefix(line, "//go:embed ")
+	})
+}
+
+// ConstDecls creates the declarations for a const section: one merged block
+// holding the specs, then the blocks that must stay intact. spaceMultiline is
+// passed on to MergeConstSpecs.
+func ConstDecls(specs []*dst.ValueSpec, blocks []*dst.GenDecl, comment string, spaceMultiline bool) []dst.Decl {
+	decls := make([]dst.Decl, 0, len(blocks)+1)
+	if len(specs) > 0 {
+		decls = append(decls, MergeConstSpecs(specs, comment, spaceMultiline))
+	}
+
+	for _, block := range blocks {
+		block.Decs.Before = dst.EmptyLine
+		decls = append(decls, block)
+	}
+
+	return decls
+}
+
+// isPositionalConstBlock reports whether the values of a const declaration
+// depend on the position of its specs: one uses iota, or has no values and
+// repeats those of the spec before it.
+func isPositionalConstBlock(genDecl *dst.GenDecl) bool {
+	if ast.IsIotaBlock(genDecl) {
+		return true
+	}
+
+	return slices.ContainsFunc(genDecl.Specs, func(spec dst.Spec) bool {
+		vspec, ok := spec.(*dst.ValueSpec)
+		return ok && len(vspec.Values) == 0
+	})
+}
+
+// firstSpecName returns the first name declared by a const or var declaration.
+func firstSpecName(genDecl *dst.GenDecl) string {
+	for _, spec := range genDecl.Specs {
+		if vspec, ok := spec.(*dst.ValueSpec); ok && len(vspec.Names) > 0 {
+			return vspec.Names[0].Name
+		}
+	}
+
+	return ""
+}
+
+// VarDecls creates the declarations for a var section: one merged block holding
+// the specs, plus a standalone var declaration for each spec with a //go:embed
+// directive, which is never merged with others. spaceMultiline is passed on to
+// MergeVarSpecs.
+func VarDecls(specs []*dst.ValueSpec, comment string, spaceMultiline bool) []dst.Decl {
+	merged, embedded := partition(specs, hasEmbedDirective)
+
+	decls := make([]dst.Decl, 0, len(embedded)+1)
+	if len(merged) > 0 {
+		decls = append(decls, MergeVarSpecs(merged, comment, spaceMultiline))
+	}
+
+	for _, spec := range embedded {
+		decl := SingleSpecDecl(spec)
+		decl.Decs.Before = dst.EmptyLine
+		decls = append(decls, decl)
+	}
+
+	return decls
+}
+
+// SingleSpecDecl creates a standalone var declaration holding spec, for specs
+// that must not be merged: interface compliance assertions and //go:embed vars.
+func SingleSpecDecl(spec *dst.ValueSpec) *dst.GenDecl {
+	decl := &dst.GenDecl{
+		Tok:   token.VAR,
+		Specs: []dst.Spec{spec},
+	}
+	// Doc comments belong before the var keyword, not the spec
+	decl.Decs.Start = spec.Decs.Start
+	spec.Decs.Start = nil
+	spec.Decs.Before = dst.None
+	spec.Decs.After = dst.None
+
+	return decl
+}
+
+// MergeConstSpecs creates a single const block from multiple specs. When
+// spaceMultiline is set, specs whose values span several lines get a blank line
+// before and after them.
+func MergeConstSpecs(specs []*dst.ValueSpec, comment string, spaceMultiline bool) *dst.GenDecl {
+	return mergeSpecs(token.CONST, specs, comment, spaceMultiline)
+}
+
+// MergeVarSpecs creates a single var block from multiple specs. When
+// spaceMultiline is set, specs whose values span several lines get a blank line
+// before and after them.
+func MergeVarSpecs(specs []*dst.ValueSpec, comment string, spaceMultiline bool) *dst.GenDecl {
+	return mergeSpecs(token.VAR, specs, comment, spaceMultiline)
+}
+
+// mergeSpecs creates a single block of tok from multiple specs.
+func mergeSpecs(tok token.Token, specs []*dst.ValueSpec, comment string, spaceMultiline bool) *dst.GenDecl {
+	decl := newGenDeclTemplate(tok)
+	decl.Specs = spaceSpecs(specs, spaceMultiline)
 	decl.Decs.Before = dst.EmptyLine
 	decl.Decs.Start = nil
 	decl.Decs.Start.Append("// " + comment)
 
 	return decl
 }
+
+// spaceSpecs lines up specs gathered from several declarations for one block,
+// each on its own line. Specs with floating comments get a blank line above,
+// and multi-line specs get blank lines around them when spaceMultiline is set.
+func spaceSpecs(specs []*dst.ValueSpec, spaceMultiline bool) []dst.Spec {
+	dstSpecs := make([]dst.Spec, 0, len(specs))
+
+	previousMultiline := false
+	for i, spec := range specs {
+		multiline := spaceMultiline && isMultilineSpec(spec)
+
+		// Floating comments need a blank line above to stay apart from the previous spec
+		spec.Decs.Before = dst.NewLine
+		if i > 0 && (multiline || previousMultiline || hasBlankLine(spec.Decs.Start)) {
+			spec.Decs.Before = dst.EmptyLine
+		}
+		spec.Decs.After = dst.NewLine
+		dstSpecs = append(dstSpecs, spec)
+
+		previousMultiline = multiline
+	}
+
+	return dstSpecs
+}
+
+// isMultilineSpec reports whether a spec's type or values span several lines.
+func isMultilineSpec(spec *dst.ValueSpec) bool {
+	if ast.IsMultiline(spec.Type) {
+		return true
+	}
+
+	return slices.ContainsFunc(spec.Values, ast.IsMultiline)
+}
@@ -2,6 +2,7 @@ package categorize
 
 import (
 	"go/token"
+	"slices"
 	"testing"
 
 	"github.com/dave/dst"
@@ -23,20 +24,22 @@ func parseSource(t *testing.T, src string) *dst.File {
 
 func TestCategorizeDeclarations(t *testing.T) {
 	tests := []struct {
-		name                 string
-		src                  string
-		expectedMain         bool
-		expectedInitCount    int
-		expectedExpConsts    int
-		expectedUnexpConsts  int
-		expectedExpVars      int
-		expectedUnexpVars    int
-		expectedExpTypes     int
-		expectedUnexpTypes   int
-		expectedExpEnums     int
-		expectedUnexpEnums   int
-		expectedExpFuncs     int
-		expectedUnexpFuncs   int
+		name                string
+		src                 string
+		expectedMain        bool
+		expectedInitCount   int
+		expectedExpConsts   int
+		expectedUnexpConsts int
+		expectedExpBlocks   int
+		expectedUnexpBlocks int
+		expectedExpVars     int
+		expectedUnexpVars   int
+		expectedExpTypes    int
+		expectedUnexpTypes  int
+		expectedExpEnums    int
+		expectedUnexpEnums  int
+		expectedExpFuncs    int
+		expectedUnexpFuncs  int
 	}{
 		{
 			name: "basic categorization",
@@ -139,7 +142,7 @@ const (
 	c
 )
 `,
-			expectedUnexpConsts: 3, // Should be regular constants, not an enum
+			expectedUnexpBlocks: 1, // Kept intact as constants, not an enum
 			expectedUnexpEnums:  0, // No enum since no type annotation
 		},
 		{
@@ -152,10 +155,24 @@ const (
 	C
 )
 `,
-			expectedExpConsts:  3,
+			expectedExpBlocks:  1,
 			expectedUnexpEnums: 0,
 			expectedExpEnums:   0,
 		},
+		{
+			name: "implicitly repeated values kept intact without iota",
+			src: `package test
+
+const (
+	B = 1
+	A
+)
+
+const C = 2
+`,
+			expectedExpConsts: 1, // C is merged
+			expectedExpBlocks: 1, // A repeats B's value, so the block stays whole
+		},
 		{
 			name: "constructor matching with ambiguous types - longest match wins",
 			src: `package test
@@ -194,6 +211,12 @@ func NewFooBarWithOptions() *FooBar { return nil }
 			if len(cat.UnexportedConsts) != tt.expectedUnexpConsts {
 				t.Errorf("unexported consts = %d, want %d", len(cat.UnexportedConsts), tt.expectedUnexpConsts)
 			}
+			if len(cat.ExportedConstBlocks) != tt.expectedExpBlocks {
+				t.Errorf("exported const blocks = %d, want %d", len(cat.ExportedConstBlocks), tt.expectedExpBlocks)
+			}
+			if len(cat.UnexportedConstBlocks) != tt.expectedUnexpBlocks {
+				t.Errorf("unexported const blocks = %d, want %d", len(cat.UnexportedConstBlocks), tt.expectedUnexpBlocks)
+			}
 
 			if len(cat.ExportedVars) != tt.expectedExpVars {
 				t.Errorf("exported vars = %d, want %d", len(cat.ExportedVars), tt.expectedExpVars)
@@ -410,7 +433,7 @@ func ExportedFunc() {}
 		"exported_funcs":  true,
 	}
 
-	CollectUncategorized(cat, includedSections)
+	CollectUncategorized(cat, includedSections, Spacing{})
 
 	if len(cat.ExportedConsts) != 0 {
 		t.Errorf("exported consts should be empty after collect, got %d", len(cat.ExportedConsts))
@@ -490,8 +513,8 @@ func (s Status) isValid() bool { return true }
 		t.Error("type decl should not be nil")
 	}
 
-	if eg.ConstDecl == nil {
-		t.Error("const decl should not be nil")
+	if len(eg.ConstDecls) != 1 {
+		t.Errorf("const decls = %d, want 1", len(eg.ConstDecls))
 	}
 
 	if len(eg.ExportedMethods) != 1 {
@@ -503,6 +526,158 @@ func (s Status) isValid() bool { return true }
 	}
 }
 
+func TestAssertionGrouping(t *testing.T) {
+	src := `package test
+
+import "io"
+
+var (
+	_ io.Reader = (*Reader)(nil)
+	_ io.Closer = &Reader{}
+	_ io.Writer = (*ext.Writer)(nil)
+	count       = 0
+)
+
+var _ fmt.Stringer = Status(0)
+
+type Reader struct{}
+
+type Status int
+
+const (
+	StatusOK Status = iota
+)
+`
+
+	t.Run("grouped with type and enum", func(t *testing.T) {
+		cat := CategorizeDeclarations(parseSource(t, src))
+
+		if len(cat.ExportedTypes) != 1 || len(cat.ExportedTypes[0].Assertions) != 2 {
+			t.Fatalf("expected Reader to have 2 assertions, got %+v", cat.ExportedTypes)
+		}
+		if len(cat.ExportedEnums) != 1 || len(cat.ExportedEnums[0].Assertions) != 1 {
+			t.Fatalf("expected Status to have 1 assertion, got %+v", cat.ExportedEnums)
+		}
+		// The external assertion and the regular var stay in the var block
+		if len(cat.UnexportedVars) != 2 {
+			t.Errorf("unexported vars = %d, want 2", len(cat.UnexportedVars))
+		}
+	})
+
+	t.Run("disabled for enums", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.AssertionsWithEnums = false
+		cat := CategorizeDeclarationsWithConfig(parseSource(t, src), cfg)
+
+		if len(cat.ExportedEnums[0].Assertions) != 0 {
+			t.Errorf("enum assertions = %d, want 0", len(cat.ExportedEnums[0].Assertions))
+		}
+		if len(cat.UnexportedVars) != 3 {
+			t.Errorf("unexported vars = %d, want 3", len(cat.UnexportedVars))
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.AssertionsWithTypes = false
+		cfg.AssertionsWithEnums = false
+		cat := CategorizeDeclarationsWithConfig(parseSource(t, src), cfg)
+
+		if len(cat.ExportedTypes[0].Assertions) != 0 {
+			t.Errorf("type assertions = %d, want 0", len(cat.ExportedTypes[0].Assertions))
+		}
+		if len(cat.UnexportedVars) != 5 {
+			t.Errorf("unexported vars = %d, want 5", len(cat.UnexportedVars))
+		}
+	})
+}
+
+func TestEnumGroup_MultipleConstBlocks(t *testing.T) {
+	src := `package test
+
+const (
+	OpRead Op = iota
+	OpWrite
+)
+
+type Op int
+
+// Reserved range.
+const (
+	OpReserved Op = iota + 100
+	OpInternal
+)
+`
+
+	cat := CategorizeDeclarations(parseSource(t, src))
+
+	if len(cat.ExportedEnums) != 1 {
+		t.Fatalf("expected 1 enum group, got %d", len(cat.ExportedEnums))
+	}
+
+	eg := cat.ExportedEnums[0]
+	if len(eg.ConstDecls) != 2 {
+		t.Fatalf("const decls = %d, want 2", len(eg.ConstDecls))
+	}
+
+	first := eg.ConstDecls[0].Specs[0].(*dst.ValueSpec).Names[0].Name
+	second := eg.ConstDecls[1].Specs[0].(*dst.ValueSpec).Names[0].Name
+	if first != "OpRead" || second != "OpReserved" {
+		t.Errorf("const blocks out of source order: %s, %s", first, second)
+	}
+}
+
+func TestTypedBlockEnums(t *testing.T) {
+	src := `package test
+
+type Status string
+
+const (
+	StatusOK  Status = "ok"
+	StatusErr Status = "err"
+)
+
+const (
+	Timeout time.Duration = 5
+	Retries time.Duration = 3
+)
+
+func (s Status) String() string { return string(s) }
+`
+
+	t.Run("not detected by default", func(t *testing.T) {
+		cat := CategorizeDeclarations(parseSource(t, src))
+
+		if len(cat.ExportedEnums) != 0 {
+			t.Errorf("exported enums = %d, want 0", len(cat.ExportedEnums))
+		}
+		if len(cat.ExportedConsts) != 4 {
+			t.Errorf("exported consts = %d, want 4", len(cat.ExportedConsts))
+		}
+	})
+
+	t.Run("detected when enabled", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.TypedBlockEnums = true
+		cat := CategorizeDeclarationsWithConfig(parseSource(t, src), cfg)
+
+		if len(cat.ExportedEnums) != 1 {
+			t.Fatalf("exported enums = %d, want 1", len(cat.ExportedEnums))
+		}
+		eg := cat.ExportedEnums[0]
+		if eg.TypeName != "Status" || eg.TypeDecl == nil || len(eg.ExportedMethods) != 1 {
+			t.Errorf("enum group not paired with type and methods: %+v", eg)
+		}
+		if len(cat.ExportedTypes) != 0 {
+			t.Errorf("exported types = %d, want 0", len(cat.ExportedTypes))
+		}
+		// time.Duration is not file-local, so those stay constants
+		if len(cat.ExportedConsts) != 2 {
+			t.Errorf("exported consts = %d, want 2", len(cat.ExportedConsts))
+		}
+	})
+}
+
 func TestConstructorMatching_LongestMatchWins(t *testing.T) {
 	// Test that constructor matching uses longest-suffix match
 	// NewFooBar should match FooBar, not Foo
@@ -782,3 +957,845 @@ func unexportedFunc2() {}
 		CategorizeDeclarations(file)
 	}
 }
+
+func TestConstructorMatching_Configurable(t *testing.T) {
+	src := `package test
+
+type List[T any] struct{}
+
+type Config struct{}
+
+type store interface{ Get() }
+
+type memStore struct{}
+
+func NewList[T any]() *List[T] { return nil }
+
+func ParseConfig(s string) (*Config, error) { return nil, nil }
+
+func LoadConfig() (error, *Config) { return nil, nil }
+
+func NewMemStore() store { return memStore{} }
+`
+
+	constructorsOf := func(cat *CategorizedDecls, typeName string) []string {
+		var names []string
+		for _, tg := range append(cat.ExportedTypes, cat.UnexportedTypes...) {
+			if tg.TypeName == typeName {
+				for _, ctor := range tg.Constructors {
+					names = append(names, ctor.Name.Name)
+				}
+			}
+		}
+		return names
+	}
+
+	t.Run("generic return matched by default", func(t *testing.T) {
+		cat := CategorizeDeclarations(parseSource(t, src))
+
+		if got := constructorsOf(cat, "List"); len(got) != 1 || got[0] != "NewList" {
+			t.Errorf("List constructors = %v, want [NewList]", got)
+		}
+		if got := constructorsOf(cat, "Config"); len(got) != 0 {
+			t.Errorf("Config constructors = %v, want none", got)
+		}
+		if got := constructorsOf(cat, "store"); len(got) != 1 {
+			t.Errorf("store constructors = %v, want [NewMemStore]", got)
+		}
+	})
+
+	t.Run("custom prefixes", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.ConstructorPrefixes = []string{"New", "Parse"}
+		cat := CategorizeDeclarationsWithConfig(parseSource(t, src), cfg)
+
+		if got := constructorsOf(cat, "Config"); len(got) != 1 || got[0] != "ParseConfig" {
+			t.Errorf("Config constructors = %v, want [ParseConfig]", got)
+		}
+	})
+
+	t.Run("any return position", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.ConstructorPrefixes = []string{"Load"}
+		cfg.ConstructorAnyReturn = true
+		cat := CategorizeDeclarationsWithConfig(parseSource(t, src), cfg)
+
+		if got := constructorsOf(cat, "Config"); len(got) != 1 || got[0] != "LoadConfig" {
+			t.Errorf("Config constructors = %v, want [LoadConfig]", got)
+		}
+	})
+
+	t.Run("interface factories attach to concrete type", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.ConstructorReturnsInterface = false
+		cat := CategorizeDeclarationsWithConfig(parseSource(t, src), cfg)
+
+		if got := constructorsOf(cat, "store"); len(got) != 0 {
+			t.Errorf("store constructors = %v, want none", got)
+		}
+		if got := constructorsOf(cat, "memStore"); len(got) != 1 || got[0] != "NewMemStore" {
+			t.Errorf("memStore constructors = %v, want [NewMemStore]", got)
+		}
+	})
+
+	t.Run("zero config attaches interface factories to concrete type", func(t *testing.T) {
+		cat := CategorizeDeclarationsWithConfig(parseSource(t, src), &Config{ConstructorPrefixes: []string{"New"}})
+
+		if got := constructorsOf(cat, "store"); len(got) != 0 {
+			t.Errorf("store constructors = %v, want none", got)
+		}
+		if got := constructorsOf(cat, "memStore"); len(got) != 1 || got[0] != "NewMemStore" {
+			t.Errorf("memStore constructors = %v, want [NewMemStore]", got)
+		}
+	})
+}
+
+func TestOptionGrouping(t *testing.T) {
+	src := `package test
+
+type Server struct{}
+
+type Option func(*Server)
+
+type ClientOption interface{ apply() }
+
+func WithTimeout(d int) Option { return nil }
+
+func WithLogger() Option { return nil }
+
+func WithRetries(n int) ClientOption { return nil }
+
+func Helper() int { return 0 }
+`
+
+	groupOf := func(cat *CategorizedDecls, typeName string) *TypeGroup {
+		for _, tg := range cat.ExportedTypes {
+			if tg.TypeName == typeName {
+				return tg
+			}
+		}
+		return nil
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		cat := CategorizeDeclarations(parseSource(t, src))
+
+		if len(cat.ExportedFuncs) != 4 {
+			t.Errorf("exported funcs = %d, want 4", len(cat.ExportedFuncs))
+		}
+	})
+
+	t.Run("func types", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.OptionsWithTypes = true
+		cat := CategorizeDeclarationsWithConfig(parseSource(t, src), cfg)
+
+		opts := groupOf(cat, "Option").Options
+		if len(opts) != 2 || opts[0].Name.Name != "WithLogger" || opts[1].Name.Name != "WithTimeout" {
+			t.Errorf("Option options = %v, want [WithLogger WithTimeout]", opts)
+		}
+		if len(cat.ExportedFuncs) != 2 {
+			t.Errorf("exported funcs = %d, want 2", len(cat.ExportedFuncs))
+		}
+	})
+
+	t.Run("name patterns", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.OptionsWithTypes = true
+		cfg.OptionTypePatterns = []string{"*Option"}
+		cat := CategorizeDeclarationsWithConfig(parseSource(t, src), cfg)
+
+		if opts := groupOf(cat, "ClientOption").Options; len(opts) != 1 {
+			t.Errorf("ClientOption options = %d, want 1", len(opts))
+		}
+		if len(cat.ExportedFuncs) != 1 {
+			t.Errorf("exported funcs = %d, want 1", len(cat.ExportedFuncs))
+		}
+	})
+}
+
+func TestEnumConstructorsKept(t *testing.T) {
+	src := `package test
+
+type Status int
+
+const (
+	StatusOK Status = iota
+)
+
+func NewStatus() Status { return StatusOK }
+`
+
+	cat := CategorizeDeclarations(parseSource(t, src))
+
+	// Enum groups have no constructor slot, so the constructor stays standalone
+	if len(cat.ExportedFuncs) != 1 || cat.ExportedFuncs[0].Name.Name != "NewStatus" {
+		t.Errorf("exported funcs = %v, want [NewStatus]", cat.ExportedFuncs)
+	}
+}
+
+func TestTestFunctionSections(t *testing.T) {
+	src := `package foo
+
+import "testing"
+
+func TestMain(m *testing.M) {}
+func TestParse(t *testing.T) {}
+func Test_parse(t *testing.T) {}
+func Testing() {}
+func BenchmarkParse(b *testing.B) {}
+func FuzzParse(f *testing.F) {}
+func Example() {}
+func ExampleParse() {}
+func ExampleWithArgs(n int) {}
+func newFixture(tb testing.TB) string { return "" }
+func Helper() {}
+`
+
+	t.Run("test file", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.TestFile = true
+		cat := CategorizeDeclarationsWithConfig(parseSource(t, src), cfg)
+
+		if cat.TestMain == nil {
+			t.Error("expected TestMain")
+		}
+		if len(cat.Tests) != 2 || cat.Tests[0].Name.Name != "TestParse" {
+			t.Errorf("tests = %d, want [TestParse Test_parse]", len(cat.Tests))
+		}
+		if len(cat.Benchmarks) != 1 || len(cat.FuzzTests) != 1 {
+			t.Errorf("benchmarks = %d, fuzz tests = %d, want 1 each", len(cat.Benchmarks), len(cat.FuzzTests))
+		}
+		if len(cat.Examples) != 2 {
+			t.Errorf("examples = %d, want 2", len(cat.Examples))
+		}
+		if len(cat.TestHelpers) != 1 || cat.TestHelpers[0].Name.Name != "newFixture" {
+			t.Errorf("test helpers = %d, want [newFixture]", len(cat.TestHelpers))
+		}
+		// Testing, ExampleWithArgs and Helper don't have test signatures
+		if len(cat.ExportedFuncs) != 3 {
+			t.Errorf("exported funcs = %d, want 3", len(cat.ExportedFuncs))
+		}
+	})
+
+	t.Run("non-test file", func(t *testing.T) {
+		cat := CategorizeDeclarations(parseSource(t, src))
+
+		if cat.TestMain != nil || len(cat.Tests) > 0 || len(cat.TestHelpers) > 0 {
+			t.Error("expected no test sections outside test files")
+		}
+		if len(cat.ExportedFuncs) != 10 {
+			t.Errorf("exported funcs = %d, want 10", len(cat.ExportedFuncs))
+		}
+	})
+}
+
+func TestIsTestFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected bool
+	}{
+		{"external test package", "package foo_test\n", true},
+		{"test function", "package foo\nimport \"testing\"\nfunc TestFoo(t *testing.T) {}\n", true},
+		{"benchmark", "package foo\nimport \"testing\"\nfunc BenchmarkFoo(b *testing.B) {}\n", true},
+		{"helper only", "package foo\nimport \"testing\"\nfunc helper(t *testing.T) {}\n", false},
+		{"plain file", "package foo\nfunc TestFoo() {}\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTestFile(parseSource(t, tt.src)); got != tt.expected {
+				t.Errorf("IsTestFile() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTestTargetPosition(t *testing.T) {
+	positions := map[string]int{
+		"User":        0,
+		"NewUser":     1,
+		"User.String": 2,
+		"Parse":       3,
+		"ParseHeader": 4,
+		"parse":       5,
+	}
+
+	tests := []struct {
+		name     string
+		expected int
+	}{
+		{"User", 0},
+		{"User_String", 2},
+		{"User_Unknown", 0},
+		{"NewUser", 1},
+		{"Parse", 3},
+		{"ParseEmpty", 3},
+		{"ParseHeader", 4},
+		{"Parse_header", 3},
+		{"_parse", 5},
+		{"Parser", 6},
+		{"Alpha", 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testTargetPosition(tt.name, positions); got != tt.expected {
+				t.Errorf("testTargetPosition(%q) = %d, want %d", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSortTestsByTarget(t *testing.T) {
+	src := `package foo
+
+import "testing"
+
+func TestAlpha(t *testing.T) {}
+func TestParse(t *testing.T) {}
+func TestUser_String(t *testing.T) {}
+func TestUser(t *testing.T) {}
+func BenchmarkUser(b *testing.B) {}
+func BenchmarkParse(b *testing.B) {}
+`
+
+	cfg := DefaultConfig()
+	cfg.TestFile = true
+	cfg.TestTargets = map[string]int{"User": 0, "User.String": 1, "Parse": 2}
+	cat := CategorizeDeclarationsWithConfig(parseSource(t, src), cfg)
+
+	var names []string
+	for _, fn := range slices.Concat(cat.Tests, cat.Benchmarks) {
+		names = append(names, fn.Name.Name)
+	}
+
+	expected := []string{"TestUser", "TestUser_String", "TestParse", "TestAlpha", "BenchmarkUser", "BenchmarkParse"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("order = %v, want %v", names, expected)
+	}
+}
+
+func TestExampleTarget(t *testing.T) {
+	tests := []struct {
+		name                     string
+		typeName, method, suffix string
+	}{
+		{"Example", "", "", ""},
+		{"Example_second", "", "", "second"},
+		{"ExampleParse", "Parse", "", ""},
+		{"ExampleParse_strict", "Parse", "", "strict"},
+		{"ExampleUser_String", "User", "String", ""},
+		{"ExampleUser_String_empty", "User", "String", "empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typeName, method, suffix := exampleTarget(tt.name)
+			if typeName != tt.typeName || method != tt.method || suffix != tt.suffix {
+				t.Errorf("exampleTarget(%q) = (%q, %q, %q), want (%q, %q, %q)",
+					tt.name, typeName, method, suffix, tt.typeName, tt.method, tt.suffix)
+			}
+		})
+	}
+}
+
+func TestSortExamplesByTarget(t *testing.T) {
+	src := `package foo_test
+
+func ExampleUser_String() {}
+func ExampleAlpha() {}
+func Example_second() {}
+func ExampleUser() {}
+func ExampleUserList() {}
+func Example() {}
+`
+
+	names := func(funcs []*dst.FuncDecl) []string {
+		var result []string
+		for _, fn := range funcs {
+			result = append(result, fn.Name.Name)
+		}
+		return result
+	}
+
+	t.Run("by name", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.TestFile = true
+		cfg.ExamplesByTarget = true
+		cat := CategorizeDeclarationsWithConfig(parseSource(t, src), cfg)
+
+		expected := []string{"Example", "Example_second", "ExampleAlpha", "ExampleUser", "ExampleUser_String", "ExampleUserList"}
+		if got := names(cat.Examples); !slices.Equal(got, expected) {
+			t.Errorf("order = %v, want %v", got, expected)
+		}
+	})
+
+	t.Run("by package position", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.TestFile = true
+		cfg.ExamplesByTarget = true
+		cfg.ExampleTargets = map[string]int{"User": 0, "User.String": 1, "Alpha": 2}
+		cat := CategorizeDeclarationsWithConfig(parseSource(t, src), cfg)
+
+		expected := []string{"Example", "Example_second", "ExampleUser", "ExampleUser_String", "ExampleAlpha", "ExampleUserList"}
+		if got := names(cat.Examples); !slices.Equal(got, expected) {
+			t.Errorf("order = %v, want %v", got, expected)
+		}
+	})
+}
+
+func TestSortSpecsByDependency(t *testing.T) {
+	src := `package foo
+
+var (
+	addr        = host + ":" + port
+	port        = "8080"
+	host        = defaultHost
+	defaultHost = "localhost"
+	zeta        = 1
+	cycleA      = cycleB
+	cycleB      = cycleA
+)
+`
+
+	cfg := DefaultConfig()
+	cfg.VarsByDependency = true
+	cat := CategorizeDeclarationsWithConfig(parseSource(t, src), cfg)
+
+	var names []string
+	for _, spec := range cat.UnexportedVars {
+		names = append(names, spec.Names[0].Name)
+	}
+
+	// The cycle is broken only once nothing else is ready
+	expected := []string{"defaultHost", "host", "port", "addr", "zeta", "cycleA", "cycleB"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("order = %v, want %v", names, expected)
+	}
+}
+
+func TestSortFuncsStepdown(t *testing.T) {
+	src := `package foo
+
+type S struct{}
+
+func (s *S) Do() { helperForS() }
+
+func Run() {
+	parse()
+	render()
+}
+
+func Process() { Run() }
+
+func helperForS() {}
+func parse()      { tokenize() }
+func render()     { generic[int]() }
+func tokenize()   {}
+func generic[T any]() {}
+func unused()     {}
+func alpha()      { beta() }
+func beta()       { alpha() }
+`
+
+	cfg := DefaultConfig()
+	cfg.FuncsStepdown = true
+	cat := CategorizeDeclarationsWithConfig(parseSource(t, src), cfg)
+
+	names := func(funcs []*dst.FuncDecl) []string {
+		var result []string
+		for _, fn := range funcs {
+			result = append(result, fn.Name.Name)
+		}
+		return result
+	}
+
+	if got, want := names(cat.ExportedFuncs), []string{"Process", "Run"}; !slices.Equal(got, want) {
+		t.Errorf("exported funcs = %v, want %v", got, want)
+	}
+
+	// helperForS is reached from the S method, which is read first; the alpha/beta
+	// cycle has no outside caller and comes last
+	want := []string{"helperForS", "parse", "tokenize", "render", "generic", "unused", "alpha", "beta"}
+	if got := names(cat.UnexportedFuncs); !slices.Equal(got, want) {
+		t.Errorf("unexported funcs = %v, want %v", got, want)
+	}
+}
+
+func TestSortTypesByDependency(t *testing.T) {
+	src := `package foo
+
+type server struct {
+	config config
+	cache  *cache[entry]
+	handler
+}
+
+type handler interface{ Serve(req request) response }
+
+type request struct{ node *node }
+type response struct{}
+
+type node struct{ next *tree }
+type tree struct{ root *node }
+
+type cache[T comparable] struct{ items map[T]entry }
+
+type entry struct{}
+
+type config struct{ entry int }
+
+func (r *remote) Close() error { return nil }
+`
+
+	cfg := DefaultConfig()
+	cfg.TypesByDependency = true
+	cat := CategorizeDeclarationsWithConfig(parseSource(t, src), cfg)
+
+	var got []string
+	for _, tg := range cat.UnexportedTypes {
+		got = append(got, tg.TypeName)
+	}
+
+	// config's field named entry is not a reference; node and tree form a cycle
+	// and stay together alphabetically; remote is declared in another file
+	want := []string{"config", "entry", "cache", "node", "tree", "remote", "request", "response", "handler", "server"}
+	if !slices.Equal(got, want) {
+		t.Errorf("types = %v, want %v", got, want)
+	}
+}
+
+func TestGroupInterfaceMethods(t *testing.T) {
+	src := `package foo
+
+type Shape interface {
+	Area() float64
+	Perimeter() float64
+}
+
+type Named interface {
+	Shape
+	Name() string
+}
+
+type Number interface{ ~int }
+
+type square struct{}
+
+func (s square) helper()            {}
+func (s square) String() string     { return "" }
+func (s square) Perimeter() float64 { return 0 }
+func (s square) Name() string       { return "" }
+func (s square) Area() float64      { return 0 }
+func (s square) Extra()             {}
+
+type pq []int
+
+func (p pq) Swap(i, j int)      {}
+func (p *pq) Pop() any          { return nil }
+func (p pq) Less(i, j int) bool { return false }
+func (p *pq) Push(x any)        {}
+func (p pq) Len() int           { return 0 }
+`
+
+	cfg := DefaultConfig()
+	cfg.InterfaceMethods = true
+	cfg.InterfaceMethodSets = map[string][]string{
+		"fmt.Stringer":   {"String"},
+		"sort.Interface": {"Len", "Less", "Swap"},
+		"heap.Interface": {"Len", "Less", "Swap", "Push", "Pop"},
+	}
+	cat := CategorizeDeclarationsWithConfig(parseSource(t, src), cfg)
+
+	names := func(funcs []*dst.FuncDecl) []string {
+		var result []string
+		for _, fn := range funcs {
+			result = append(result, fn.Name.Name)
+		}
+		return result
+	}
+
+	sets := make(map[string][]string)
+	var order []string
+	for _, tg := range cat.UnexportedTypes {
+		for _, set := range tg.InterfaceMethods {
+			key := tg.TypeName + ":" + set.Interface
+			order = append(order, key)
+			sets[key] = names(set.Methods)
+		}
+		if tg.TypeName == "square" {
+			if got := names(tg.ExportedMethods); !slices.Equal(got, []string{"Extra"}) {
+				t.Errorf("square exported methods = %v, want [Extra]", got)
+			}
+			if got := names(tg.UnexportedMethods); !slices.Equal(got, []string{"helper"}) {
+				t.Errorf("square unexported methods = %v, want [helper]", got)
+			}
+		}
+	}
+
+	// Named includes Shape's methods, so it claims Area and Perimeter first and
+	// Shape has nothing left; heap.Interface claims the sort.Interface methods
+	want := []string{"pq:heap.Interface", "square:Named", "square:fmt.Stringer"}
+	if !slices.Equal(order, want) {
+		t.Errorf("interface sets = %v, want %v", order, want)
+	}
+	if got := sets["pq:heap.Interface"]; !slices.Equal(got, []string{"Len", "Less", "Swap", "Push", "Pop"}) {
+		t.Errorf("heap.Interface methods = %v", got)
+	}
+	if got := sets["square:Named"]; !slices.Equal(got, []string{"Area", "Perimeter", "Name"}) {
+		t.Errorf("Named methods = %v", got)
+	}
+}
+
+func TestSplitGenerics(t *testing.T) {
+	src := `package foo
+
+type List[T any] struct{ items []T }
+
+func NewList[T any]() *List[T] { return nil }
+
+func (l *List[T]) Push(v T) {}
+
+type Config struct{}
+
+type set[T comparable] map[T]struct{}
+
+func Map[T, U any](in []T, f func(T) U) []U { return nil }
+
+func Plain() {}
+
+func filter[T any](in []T) []T { return nil }
+`
+
+	typeNames := func(groups []*TypeGroup) []string {
+		var result []string
+		for _, tg := range groups {
+			result = append(result, tg.TypeName)
+		}
+		return result
+	}
+	funcNames := func(funcs []*dst.FuncDecl) []string {
+		var result []string
+		for _, fn := range funcs {
+			result = append(result, fn.Name.Name)
+		}
+		return result
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		cat := CategorizeDeclarationsWithConfig(parseSource(t, src), DefaultConfig())
+		if got := funcNames(cat.ExportedFuncs); !slices.Equal(got, []string{"Map", "Plain"}) {
+			t.Errorf("exported funcs = %v, want [Map Plain]", got)
+		}
+		if got := typeNames(cat.ExportedTypes); !slices.Equal(got, []string{"Config", "List"}) {
+			t.Errorf("exported types = %v, want [Config List]", got)
+		}
+	})
+
+	t.Run("exported only", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.ExportedGenericFuncs = true
+		cfg.ExportedGenericTypes = true
+		cat := CategorizeDeclarationsWithConfig(parseSource(t, src), cfg)
+
+		if got := funcNames(cat.ExportedGenericFuncs); !slices.Equal(got, []string{"Map"}) {
+			t.Errorf("exported generic funcs = %v, want [Map]", got)
+		}
+		if got := funcNames(cat.ExportedFuncs); !slices.Equal(got, []string{"Plain"}) {
+			t.Errorf("exported funcs = %v, want [Plain]", got)
+		}
+		if got := typeNames(cat.ExportedGenericTypes); !slices.Equal(got, []string{"List"}) {
+			t.Errorf("exported generic types = %v, want [List]", got)
+		}
+		// Constructors and methods stay with the generic type
+		if list := cat.ExportedGenericTypes[0]; len(list.Constructors) != 1 || len(list.ExportedMethods) != 1 {
+			t.Errorf("List group lost its constructor or method: %+v", list)
+		}
+		// Unexported generics stay put
+		if got := funcNames(cat.UnexportedFuncs); !slices.Equal(got, []string{"filter"}) {
+			t.Errorf("unexported funcs = %v, want [filter]", got)
+		}
+		if got := typeNames(cat.UnexportedTypes); !slices.Equal(got, []string{"set"}) {
+			t.Errorf("unexported types = %v, want [set]", got)
+		}
+	})
+}
+
+func TestGroupInterfaceMethodsGenericEmbedded(t *testing.T) {
+	src := `package foo
+
+type Container[T any] interface {
+	Push(v T)
+	Pop() T
+}
+
+type Stack[T any] interface {
+	Container[T]
+	Peek() T
+}
+
+type stack[T any] []T
+
+func (s *stack[T]) Peek() T  { var v T; return v }
+func (s *stack[T]) Pop() T   { var v T; return v }
+func (s *stack[T]) Push(v T) {}
+`
+
+	cfg := DefaultConfig()
+	cfg.InterfaceMethods = true
+	cat := CategorizeDeclarationsWithConfig(parseSource(t, src), cfg)
+
+	tg := cat.UnexportedTypes[0]
+	if len(tg.InterfaceMethods) != 1 || tg.InterfaceMethods[0].Interface != "Stack" {
+		t.Fatalf("expected one Stack set, got %+v", tg.InterfaceMethods)
+	}
+
+	var got []string
+	for _, m := range tg.InterfaceMethods[0].Methods {
+		got = append(got, m.Name.Name)
+	}
+	if want := []string{"Push", "Pop", "Peek"}; !slices.Equal(got, want) {
+		t.Errorf("Stack methods = %v, want %v", got, want)
+	}
+}
+
+func TestGroupInterfaceMethodsSignatures(t *testing.T) {
+	src := `package foo
+
+type Store interface {
+	Get(key string) (int, error)
+	Put(key string, v int) error
+}
+
+type Sink[T any] interface {
+	Put(key string, v T) error
+}
+
+type Namer interface{ Name() string }
+
+type mem struct{}
+
+func (m mem) Get(key string) (int, error)    { return 0, nil }
+func (m mem) Put(key string, value int) error { return nil }
+
+type cache struct{}
+
+func (c cache) Get(key string) int              { return 0 }
+func (c cache) Put(k, v string) error            { return nil }
+func (c cache) Name(prefix string) string        { return "" }
+func (c cache) String() (s string, err error)    { return "", nil }
+`
+
+	cfg := DefaultConfig()
+	cfg.InterfaceMethods = true
+	cfg.InterfaceMethodSets = map[string][]string{"fmt.Stringer": {"String"}}
+	cat := CategorizeDeclarationsWithConfig(parseSource(t, src), cfg)
+
+	sets := make(map[string][]string)
+	for _, tg := range cat.UnexportedTypes {
+		for _, set := range tg.InterfaceMethods {
+			sets[tg.TypeName] = append(sets[tg.TypeName], set.Interface)
+		}
+	}
+
+	// mem matches Store's signatures, whatever its parameter names
+	if got := sets["mem"]; !slices.Equal(got, []string{"Store"}) {
+		t.Errorf("mem interfaces = %v, want [Store]", got)
+	}
+	// cache has methods named like Store's, Sink's and Namer's but with other
+	// signatures; fmt.Stringer is external, so it matches String by name
+	if got := sets["cache"]; !slices.Equal(got, []string{"Sink", "fmt.Stringer"}) {
+		t.Errorf("cache interfaces = %v, want [Sink fmt.Stringer]", got)
+	}
+}
+
+func TestCgoExports(t *testing.T) {
+	src := `package foo
+
+// #include <stdlib.h>
+import "C"
+
+type Handle struct{}
+
+//export NewHandle
+func NewHandle() *Handle { return nil }
+
+//export goCallback
+func goCallback(x C.int) C.int { return x }
+
+func helper() {}
+`
+
+	file := parseSource(t, src)
+	if !IsCgoFile(file) {
+		t.Fatal("IsCgoFile() = false, want true")
+	}
+	if IsCgoFile(parseSource(t, "package foo\n\nimport \"fmt\"\n")) {
+		t.Error("IsCgoFile() = true for a file without import \"C\"")
+	}
+
+	cfg := DefaultConfig()
+	cfg.CgoExports = true
+	cat := CategorizeDeclarationsWithConfig(file, cfg)
+
+	var got []string
+	for _, fn := range cat.CgoExports {
+		got = append(got, fn.Name.Name)
+	}
+	// NewHandle is a C entry point first, not a constructor
+	if want := []string{"NewHandle", "goCallback"}; !slices.Equal(got, want) {
+		t.Errorf("cgo exports = %v, want %v", got, want)
+	}
+	if len(cat.ExportedTypes) != 1 || len(cat.ExportedTypes[0].Constructors) != 0 {
+		t.Errorf("expected Handle without constructors, got %+v", cat.ExportedTypes)
+	}
+	if len(cat.UnexportedFuncs) != 1 || cat.UnexportedFuncs[0].Name.Name != "helper" {
+		t.Errorf("expected only helper in unexported funcs, got %d", len(cat.UnexportedFuncs))
+	}
+}
+
+func TestLoneDeclarationsKeepComments(t *testing.T) {
+	src := `package foo
+
+// Max is the limit.
+const Max = 10 // inclusive
+
+//go:embed static
+var staticFS embed.FS
+
+// Zed doc.
+//
+//go:generate stringer -type=Zed
+type Zed int
+
+var zeta = 1
+`
+
+	cat := CategorizeDeclarationsWithConfig(parseSource(t, src), DefaultConfig())
+
+	if got := cat.ExportedConsts[0].Decs.Start.All(); !slices.Equal(got, []string{"// Max is the limit."}) {
+		t.Errorf("const doc = %q", got)
+	}
+	if got := cat.ExportedConsts[0].Decs.End.All(); !slices.Equal(got, []string{"// inclusive"}) {
+		t.Errorf("const trailing comment = %q", got)
+	}
+	if got := cat.ExportedTypes[0].TypeDecl.Decs.Start.All(); len(got) != 3 || got[2] != "//go:generate stringer -type=Zed" {
+		t.Errorf("type doc = %q", got)
+	}
+
+	// The embedded var gets its own declaration after the merged block
+	decls := VarDecls(cat.UnexportedVars, "unexported variables.", false)
+	if len(decls) != 2 {
+		t.Fatalf("expected merged block and embed var, got %d decls", len(decls))
+	}
+	block := decls[0].(*dst.GenDecl)
+	if len(block.Specs) != 1 || block.Specs[0].(*dst.ValueSpec).Names[0].Name != "zeta" {
+		t.Errorf("expected only zeta in the merged block")
+	}
+	embedded := decls[1].(*dst.GenDecl)
+	if embedded.Lparen || !slices.Equal(embedded.Decs.Start.All(), []string{"//go:embed static"}) {
+		t.Errorf("expected standalone var with the directive, got lparen=%v start=%q", embedded.Lparen, embedded.Decs.Start.All())
+	}
+}
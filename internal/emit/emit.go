@@ -3,9 +3,12 @@ package emit
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/dave/dst"
 
+	"github.com/toejough/go-reorder/internal/ast"
 	"github.com/toejough/go-reorder/internal/categorize"
 )
 
@@ -13,6 +16,7 @@ import (
 type Config struct {
 	TypeLayout []string
 	EnumLayout []string
+	Spacing    categorize.Spacing
 }
 
 // SectionEmitter emits declarations for a section from categorized declarations.
@@ -20,20 +24,31 @@ type SectionEmitter func(*categorize.CategorizedDecls, *Config) []dst.Decl
 
 // emitters maps section names to their emitter functions.
 var emitters = map[string]SectionEmitter{
-	"imports":           emitImports,
-	"main":              emitMain,
-	"init":              emitInit,
-	"exported_consts":   emitExportedConsts,
-	"exported_enums":    emitExportedEnums,
-	"exported_vars":     emitExportedVars,
-	"exported_types":    emitExportedTypes,
-	"exported_funcs":    emitExportedFuncs,
-	"unexported_consts": emitUnexportedConsts,
-	"unexported_enums":  emitUnexportedEnums,
-	"unexported_vars":   emitUnexportedVars,
-	"unexported_types":  emitUnexportedTypes,
-	"unexported_funcs":  emitUnexportedFuncs,
-	"uncategorized":     emitUncategorized,
+	"imports":                  emitImports,
+	"main":                     emitMain,
+	"init":                     emitInit,
+	"exported_consts":          emitExportedConsts,
+	"exported_enums":           emitExportedEnums,
+	"exported_vars":            emitExportedVars,
+	"exported_types":           emitExportedTypes,
+	"exported_funcs":           emitExportedFuncs,
+	"unexported_consts":        emitUnexportedConsts,
+	"unexported_enums":         emitUnexportedEnums,
+	"unexported_vars":          emitUnexportedVars,
+	"unexported_types":         emitUnexportedTypes,
+	"unexported_funcs":         emitUnexportedFuncs,
+	"test_main":                emitTestMain,
+	"tests":                    emitTests,
+	"benchmarks":               emitBenchmarks,
+	"fuzz_tests":               emitFuzzTests,
+	"examples":                 emitExamples,
+	"test_helpers":             emitTestHelpers,
+	"exported_generic_funcs":   emitExportedGenericFuncs,
+	"unexported_generic_funcs": emitUnexportedGenericFuncs,
+	"exported_generic_types":   emitExportedGenericTypes,
+	"unexported_generic_types": emitUnexportedGenericTypes,
+	"cgo_exports":              emitCgoExports,
+	"uncategorized":            emitUncategorized,
 }
 
 // GetEmitter returns the emitter for a section name, or nil if unknown.
@@ -52,11 +67,25 @@ func EmitTypeGroup(tg *categorize.TypeGroup, layout []string) []dst.Decl {
 				tg.TypeDecl.Decs.Before = dst.EmptyLine
 				decls = append(decls, tg.TypeDecl)
 			}
+		case "assertions":
+			decls = append(decls, EmitAssertions(tg.Assertions)...)
 		case "constructors":
 			for _, ctor := range tg.Constructors {
 				ctor.Decs.Before = dst.EmptyLine
 				decls = append(decls, ctor)
 			}
+		case "options":
+			for _, opt := range tg.Options {
+				opt.Decs.Before = dst.EmptyLine
+				decls = append(decls, opt)
+			}
+		case "interface_methods":
+			for _, set := range tg.InterfaceMethods {
+				for _, method := range set.Methods {
+					method.Decs.Before = dst.EmptyLine
+					decls = append(decls, method)
+				}
+			}
 		case "exported_methods":
 			for _, method := range tg.ExportedMethods {
 				method.Decs.Before = dst.EmptyLine
@@ -67,6 +96,19 @@ func EmitTypeGroup(tg *categorize.TypeGroup, layout []string) []dst.Decl {
 				method.Decs.Before = dst.EmptyLine
 				decls = append(decls, method)
 			}
+		case "exported_value_methods", "exported_pointer_methods",
+			"unexported_value_methods", "unexported_pointer_methods":
+			methods := tg.ExportedMethods
+			if strings.HasPrefix(elem, "unexported_") {
+				methods = tg.UnexportedMethods
+			}
+			pointer := strings.HasSuffix(elem, "_pointer_methods")
+			for _, method := range methods {
+				if ast.IsPointerReceiver(method.Recv) == pointer {
+					method.Decs.Before = dst.EmptyLine
+					decls = append(decls, method)
+				}
+			}
 		}
 	}
 
@@ -96,10 +138,16 @@ func EmitEnumGroup(eg *categorize.EnumGroup, layout []string) []dst.Decl {
 				decls = append(decls, eg.TypeDecl)
 			}
 		case "iota":
-			eg.ConstDecl.Decs.Start = nil
-			eg.ConstDecl.Decs.Before = dst.EmptyLine
-			eg.ConstDecl.Decs.Start.Append(fmt.Sprintf("// %s values.", eg.TypeName))
-			decls = append(decls, eg.ConstDecl)
+			for i, constDecl := range eg.ConstDecls {
+				constDecl.Decs.Before = dst.EmptyLine
+				// The first block gets the group header unless it has a doc comment
+				if i == 0 {
+					addEnumHeader(constDecl, eg.TypeName)
+				}
+				decls = append(decls, constDecl)
+			}
+		case "assertions":
+			decls = append(decls, EmitAssertions(eg.Assertions)...)
 		case "exported_methods":
 			for _, method := range eg.ExportedMethods {
 				method.Decs.Before = dst.EmptyLine
@@ -116,6 +164,28 @@ func EmitEnumGroup(eg *categorize.EnumGroup, layout []string) []dst.Decl {
 	return decls
 }
 
+// addEnumHeader gives an enum const block the "<T> values." doc comment when
+// it has none of its own, above any directives.
+func addEnumHeader(constDecl *dst.GenDecl, typeName string) {
+	// The doc comment follows the last blank line
+	start := constDecl.Decs.Start
+	doc := 0
+	for i := range start {
+		if ast.IsBlankLine(start, i) {
+			doc = i + 1
+		}
+	}
+
+	// Empty comment lines are not a doc comment: gofmt drops them
+	if slices.ContainsFunc(start[doc:], func(line string) bool {
+		return !ast.IsDirective(line) && strings.TrimSpace(strings.TrimPrefix(line, "//")) != ""
+	}) {
+		return
+	}
+
+	constDecl.Decs.Start = slices.Concat(start[:doc], dst.Decorations{fmt.Sprintf("// %s values.", typeName)}, start[doc:])
+}
+
 // EmitEnumGroups emits all enum groups using the specified layout.
 func EmitEnumGroups(groups []*categorize.EnumGroup, layout []string) []dst.Decl {
 	decls := make([]dst.Decl, 0)
@@ -127,6 +197,22 @@ func EmitEnumGroups(groups []*categorize.EnumGroup, layout []string) []dst.Decl
 	return decls
 }
 
+// EmitAssertions emits interface compliance assertions as adjacent var declarations.
+func EmitAssertions(specs []*dst.ValueSpec) []dst.Decl {
+	decls := make([]dst.Decl, 0, len(specs))
+
+	for i, spec := range specs {
+		decl := categorize.SingleSpecDecl(spec)
+		decl.Decs.Before = dst.EmptyLine
+		if i > 0 && len(decl.Decs.Start) == 0 {
+			decl.Decs.Before = dst.NewLine
+		}
+		decls = append(decls, decl)
+	}
+
+	return decls
+}
+
 // EmitFuncs emits standalone functions with proper spacing.
 func EmitFuncs(funcs []*dst.FuncDecl) []dst.Decl {
 	decls := make([]dst.Decl, 0, len(funcs))
@@ -171,6 +257,32 @@ func Init(cat *categorize.CategorizedDecls) []dst.Decl {
 
 // Section emitter implementations
 
+// emitTypeGroups emits type groups with the configured layout and spacing.
+func emitTypeGroups(groups []*categorize.TypeGroup, cfg *Config) []dst.Decl {
+	decls := make([]dst.Decl, 0)
+
+	for _, typeGrp := range groups {
+		group := EmitTypeGroup(typeGrp, cfg.TypeLayout)
+		categorize.CompactGroup(group, cfg.Spacing)
+		decls = append(decls, group...)
+	}
+
+	return decls
+}
+
+// emitEnumGroups emits enum groups with the configured layout and spacing.
+func emitEnumGroups(groups []*categorize.EnumGroup, cfg *Config) []dst.Decl {
+	decls := make([]dst.Decl, 0)
+
+	for _, enumGrp := range groups {
+		group := EmitEnumGroup(enumGrp, cfg.EnumLayout)
+		categorize.CompactGroup(group, cfg.Spacing)
+		decls = append(decls, group...)
+	}
+
+	return decls
+}
+
 func emitImports(cat *categorize.CategorizedDecls, _ *Config) []dst.Decl {
 	return Imports(cat)
 }
@@ -183,62 +295,104 @@ func emitInit(cat *categorize.CategorizedDecls, _ *Config) []dst.Decl {
 	return Init(cat)
 }
 
-func emitExportedConsts(cat *categorize.CategorizedDecls, _ *Config) []dst.Decl {
-	if len(cat.ExportedConsts) == 0 {
-		return []dst.Decl{}
-	}
-
-	return []dst.Decl{categorize.MergeConstSpecs(cat.ExportedConsts, "Exported constants.")}
+func emitExportedConsts(cat *categorize.CategorizedDecls, cfg *Config) []dst.Decl {
+	return categorize.ConstDecls(cat.ExportedConsts, cat.ExportedConstBlocks, "Exported constants.", cfg.Spacing.SpaceMultilineSpecs)
 }
 
 func emitExportedEnums(cat *categorize.CategorizedDecls, cfg *Config) []dst.Decl {
-	return EmitEnumGroups(cat.ExportedEnums, cfg.EnumLayout)
+	return emitEnumGroups(cat.ExportedEnums, cfg)
 }
 
-func emitExportedVars(cat *categorize.CategorizedDecls, _ *Config) []dst.Decl {
+func emitExportedVars(cat *categorize.CategorizedDecls, cfg *Config) []dst.Decl {
 	if len(cat.ExportedVars) == 0 {
 		return []dst.Decl{}
 	}
 
-	return []dst.Decl{categorize.MergeVarSpecs(cat.ExportedVars, "Exported variables.")}
+	return categorize.VarDecls(cat.ExportedVars, "Exported variables.", cfg.Spacing.SpaceMultilineSpecs)
 }
 
 func emitExportedTypes(cat *categorize.CategorizedDecls, cfg *Config) []dst.Decl {
-	return EmitTypeGroups(cat.ExportedTypes, cfg.TypeLayout)
+	return emitTypeGroups(cat.ExportedTypes, cfg)
 }
 
 func emitExportedFuncs(cat *categorize.CategorizedDecls, _ *Config) []dst.Decl {
 	return EmitFuncs(cat.ExportedFuncs)
 }
 
-func emitUnexportedConsts(cat *categorize.CategorizedDecls, _ *Config) []dst.Decl {
-	if len(cat.UnexportedConsts) == 0 {
-		return []dst.Decl{}
-	}
-
-	return []dst.Decl{categorize.MergeConstSpecs(cat.UnexportedConsts, "unexported constants.")}
+func emitUnexportedConsts(cat *categorize.CategorizedDecls, cfg *Config) []dst.Decl {
+	return categorize.ConstDecls(cat.UnexportedConsts, cat.UnexportedConstBlocks, "unexported constants.", cfg.Spacing.SpaceMultilineSpecs)
 }
 
 func emitUnexportedEnums(cat *categorize.CategorizedDecls, cfg *Config) []dst.Decl {
-	return EmitEnumGroups(cat.UnexportedEnums, cfg.EnumLayout)
+	return emitEnumGroups(cat.UnexportedEnums, cfg)
 }
 
-func emitUnexportedVars(cat *categorize.CategorizedDecls, _ *Config) []dst.Decl {
+func emitUnexportedVars(cat *categorize.CategorizedDecls, cfg *Config) []dst.Decl {
 	if len(cat.UnexportedVars) == 0 {
 		return []dst.Decl{}
 	}
 
-	return []dst.Decl{categorize.MergeVarSpecs(cat.UnexportedVars, "unexported variables.")}
+	return categorize.VarDecls(cat.UnexportedVars, "unexported variables.", cfg.Spacing.SpaceMultilineSpecs)
 }
 
 func emitUnexportedTypes(cat *categorize.CategorizedDecls, cfg *Config) []dst.Decl {
-	return EmitTypeGroups(cat.UnexportedTypes, cfg.TypeLayout)
+	return emitTypeGroups(cat.UnexportedTypes, cfg)
 }
 
 func emitUnexportedFuncs(cat *categorize.CategorizedDecls, _ *Config) []dst.Decl {
 	return EmitFuncs(cat.UnexportedFuncs)
 }
 
+func emitTestMain(cat *categorize.CategorizedDecls, _ *Config) []dst.Decl {
+	if cat.TestMain == nil {
+		return []dst.Decl{}
+	}
+
+	cat.TestMain.Decs.Before = dst.EmptyLine
+
+	return []dst.Decl{cat.TestMain}
+}
+
+func emitTests(cat *categorize.CategorizedDecls, _ *Config) []dst.Decl {
+	return EmitFuncs(cat.Tests)
+}
+
+func emitBenchmarks(cat *categorize.CategorizedDecls, _ *Config) []dst.Decl {
+	return EmitFuncs(cat.Benchmarks)
+}
+
+func emitFuzzTests(cat *categorize.CategorizedDecls, _ *Config) []dst.Decl {
+	return EmitFuncs(cat.FuzzTests)
+}
+
+func emitExamples(cat *categorize.CategorizedDecls, _ *Config) []dst.Decl {
+	return EmitFuncs(cat.Examples)
+}
+
+func emitTestHelpers(cat *categorize.CategorizedDecls, _ *Config) []dst.Decl {
+	return EmitFuncs(cat.TestHelpers)
+}
+
+func emitExportedGenericFuncs(cat *categorize.CategorizedDecls, _ *Config) []dst.Decl {
+	return EmitFuncs(cat.ExportedGenericFuncs)
+}
+
+func emitUnexportedGenericFuncs(cat *categorize.CategorizedDecls, _ *Config) []dst.Decl {
+	return EmitFuncs(cat.UnexportedGenericFuncs)
+}
+
+func emitExportedGenericTypes(cat *categorize.CategorizedDecls, cfg *Config) []dst.Decl {
+	return emitTypeGroups(cat.ExportedGenericTypes, cfg)
+}
+
+func emitUnexportedGenericTypes(cat *categorize.CategorizedDecls, cfg *Config) []dst.Decl {
+	return emitTypeGroups(cat.UnexportedGenericTypes, cfg)
+}
+
+func emitCgoExports(cat *categorize.CategorizedDecls, _ *Config) []dst.Decl {
+	return EmitFuncs(cat.CgoExports)
+}
+
 func emitUncategorized(cat *categorize.CategorizedDecls, _ *Config) []dst.Decl {
 	if cat.Uncategorized == nil {
 		return []dst.Decl{}
@@ -26,6 +26,17 @@ func TestGetEmitter(t *testing.T) {
 		{"unexported_vars", true},
 		{"unexported_types", true},
 		{"unexported_funcs", true},
+		{"test_main", true},
+		{"tests", true},
+		{"benchmarks", true},
+		{"fuzz_tests", true},
+		{"examples", true},
+		{"test_helpers", true},
+		{"exported_generic_funcs", true},
+		{"unexported_generic_funcs", true},
+		{"exported_generic_types", true},
+		{"unexported_generic_types", true},
+		{"cgo_exports", true},
 		{"uncategorized", true},
 		{"bogus_section", false},
 	}
@@ -56,6 +67,7 @@ func TestEmittersHandleEmpty(t *testing.T) {
 		"exported_types", "exported_funcs",
 		"unexported_consts", "unexported_enums", "unexported_vars",
 		"unexported_types", "unexported_funcs",
+		"test_main", "tests", "benchmarks", "fuzz_tests", "examples", "test_helpers",
 		"uncategorized",
 	}
 
@@ -77,11 +89,19 @@ func TestEmitTypeGroup(t *testing.T) {
 	tg := &categorize.TypeGroup{
 		TypeName: "Server",
 		TypeDecl: &dst.GenDecl{},
+		Assertions: []*dst.ValueSpec{
+			{Names: []*dst.Ident{{Name: "_"}}},
+		},
 		Constructors: []*dst.FuncDecl{
 			{Name: &dst.Ident{Name: "NewServer"}},
 		},
+		InterfaceMethods: []*categorize.InterfaceMethodSet{
+			{Interface: "io.Closer", Methods: []*dst.FuncDecl{{Name: &dst.Ident{Name: "Close"}}}},
+		},
 		ExportedMethods: []*dst.FuncDecl{
-			{Name: &dst.Ident{Name: "Start"}},
+			{Name: &dst.Ident{Name: "Start"}, Recv: &dst.FieldList{List: []*dst.Field{
+				{Type: &dst.StarExpr{X: &dst.Ident{Name: "Server"}}},
+			}}},
 		},
 		UnexportedMethods: []*dst.FuncDecl{
 			{Name: &dst.Ident{Name: "handleRequest"}},
@@ -103,11 +123,31 @@ func TestEmitTypeGroup(t *testing.T) {
 			layout:   []string{"typedef"},
 			expected: 1,
 		},
+		{
+			name:     "typedef with assertions",
+			layout:   []string{"typedef", "assertions"},
+			expected: 2,
+		},
 		{
 			name:     "methods only",
 			layout:   []string{"exported_methods", "unexported_methods"},
 			expected: 2,
 		},
+		{
+			name:     "interface methods",
+			layout:   []string{"typedef", "interface_methods", "exported_methods"},
+			expected: 3,
+		},
+		{
+			name:     "pointer methods only",
+			layout:   []string{"exported_pointer_methods"},
+			expected: 1,
+		},
+		{
+			name:     "value methods only",
+			layout:   []string{"exported_value_methods", "unexported_value_methods"},
+			expected: 1,
+		},
 		{
 			name:     "empty layout",
 			layout:   []string{},
@@ -127,9 +167,9 @@ func TestEmitTypeGroup(t *testing.T) {
 
 func TestEmitEnumGroup(t *testing.T) {
 	eg := &categorize.EnumGroup{
-		TypeName:  "Status",
-		TypeDecl:  &dst.GenDecl{},
-		ConstDecl: &dst.GenDecl{},
+		TypeName:   "Status",
+		TypeDecl:   &dst.GenDecl{},
+		ConstDecls: []*dst.GenDecl{{}, {}},
 		ExportedMethods: []*dst.FuncDecl{
 			{Name: &dst.Ident{Name: "String"}},
 		},
@@ -146,12 +186,12 @@ func TestEmitEnumGroup(t *testing.T) {
 		{
 			name:     "full layout",
 			layout:   []string{"typedef", "iota", "exported_methods", "unexported_methods"},
-			expected: 4,
+			expected: 5,
 		},
 		{
 			name:     "typedef and iota only",
 			layout:   []string{"typedef", "iota"},
-			expected: 2,
+			expected: 3,
 		},
 		{
 			name:     "empty layout",
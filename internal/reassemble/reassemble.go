@@ -2,7 +2,7 @@
 package reassemble
 
 import (
-	"fmt"
+	"slices"
 
 	"github.com/dave/dst"
 
@@ -12,10 +12,16 @@ import (
 
 // Config holds configuration for reassembly.
 type Config struct {
-	Order      []string // Section order
-	TypeLayout []string // Layout for type groups
-	EnumLayout []string // Layout for enum groups
-	Mode       string   // Behavior mode: "preserve" or "drop"
+	Order           []string           // Section order
+	TypeLayout      []string           // Layout for type groups
+	EnumLayout      []string           // Layout for enum groups
+	Mode            string             // Behavior mode: "preserve" or "drop"
+	CompactSections bool               // No blank line between sections after the imports
+	PinImports      bool               // Emit imports first whatever the order, as cgo files need
+	Spacing         categorize.Spacing // Blank lines within groups and merged blocks
+
+	// Placed, when set, receives the declarations emitted for each section
+	Placed func(section string, decls []dst.Decl)
 }
 
 // DefaultConfig returns the default reassembly configuration.
@@ -37,171 +43,15 @@ func DefaultConfig() *Config {
 			"unexported_funcs",
 			"uncategorized",
 		},
-		TypeLayout: []string{"typedef", "constructors", "exported_methods", "unexported_methods"},
-		EnumLayout: []string{"typedef", "iota", "exported_methods", "unexported_methods"},
+		TypeLayout: []string{"typedef", "assertions", "constructors", "exported_methods", "unexported_methods"},
+		EnumLayout: []string{"typedef", "iota", "assertions", "exported_methods", "unexported_methods"},
 		Mode:       "preserve",
 	}
 }
 
 // Declarations builds the final ordered declaration list using default order.
-//
-//nolint:gocognit,cyclop,funlen // Complex by design - assembles all declaration categories in correct order
 func Declarations(cat *categorize.CategorizedDecls) []dst.Decl {
-	const extraCapacity = 10 // Extra capacity for main + merged const/var blocks
-
-	// Pre-allocate with estimated capacity
-	estimatedSize := len(cat.Imports) + len(cat.Init) + len(cat.ExportedConsts) + len(cat.ExportedEnums) +
-		len(cat.ExportedVars) + len(cat.ExportedTypes) + len(cat.ExportedFuncs) +
-		len(cat.UnexportedConsts) + len(cat.UnexportedEnums) + len(cat.UnexportedVars) +
-		len(cat.UnexportedTypes) + len(cat.UnexportedFuncs) + extraCapacity
-
-	decls := make([]dst.Decl, 0, estimatedSize)
-
-	// Imports
-	decls = append(decls, cat.Imports...)
-
-	// main() if present
-	if cat.Main != nil {
-		decls = append(decls, cat.Main)
-	}
-
-	// init() functions (preserve original order)
-	for _, initFn := range cat.Init {
-		initFn.Decs.Before = dst.EmptyLine
-		decls = append(decls, initFn)
-	}
-
-	// Exported constants (merged)
-	if len(cat.ExportedConsts) > 0 {
-		constDecl := categorize.MergeConstSpecs(cat.ExportedConsts, "Exported constants.")
-		decls = append(decls, constDecl)
-	}
-
-	// Exported enums (type + const block pairs + methods)
-	for _, enumGrp := range cat.ExportedEnums {
-		if enumGrp.TypeDecl != nil {
-			enumGrp.TypeDecl.Decs.Before = dst.EmptyLine
-			decls = append(decls, enumGrp.TypeDecl)
-		}
-		// Add comment header (clear existing first to avoid duplicates)
-		enumGrp.ConstDecl.Decs.Start = nil
-		enumGrp.ConstDecl.Decs.Before = dst.EmptyLine
-		enumGrp.ConstDecl.Decs.Start.Append(fmt.Sprintf("// %s values.", enumGrp.TypeName))
-		decls = append(decls, enumGrp.ConstDecl)
-
-		// Add methods (exported first, then unexported)
-		for _, method := range enumGrp.ExportedMethods {
-			method.Decs.Before = dst.EmptyLine
-			decls = append(decls, method)
-		}
-
-		for _, method := range enumGrp.UnexportedMethods {
-			method.Decs.Before = dst.EmptyLine
-			decls = append(decls, method)
-		}
-	}
-
-	// Exported variables (merged)
-	if len(cat.ExportedVars) > 0 {
-		varDecl := categorize.MergeVarSpecs(cat.ExportedVars, "Exported variables.")
-		decls = append(decls, varDecl)
-	}
-
-	// Exported types (with constructors and methods)
-	for _, typeGrp := range cat.ExportedTypes {
-		if typeGrp.TypeDecl != nil {
-			typeGrp.TypeDecl.Decs.Before = dst.EmptyLine
-			decls = append(decls, typeGrp.TypeDecl)
-		}
-
-		for _, ctor := range typeGrp.Constructors {
-			ctor.Decs.Before = dst.EmptyLine
-			decls = append(decls, ctor)
-		}
-
-		for _, method := range typeGrp.ExportedMethods {
-			method.Decs.Before = dst.EmptyLine
-			decls = append(decls, method)
-		}
-
-		for _, method := range typeGrp.UnexportedMethods {
-			method.Decs.Before = dst.EmptyLine
-			decls = append(decls, method)
-		}
-	}
-
-	// Exported standalone functions
-	for _, fn := range cat.ExportedFuncs {
-		fn.Decs.Before = dst.EmptyLine
-		decls = append(decls, fn)
-	}
-
-	// Unexported constants (merged)
-	if len(cat.UnexportedConsts) > 0 {
-		constDecl := categorize.MergeConstSpecs(cat.UnexportedConsts, "unexported constants.")
-		decls = append(decls, constDecl)
-	}
-
-	// Unexported enums (type + const block pairs + methods)
-	for _, enumGrp := range cat.UnexportedEnums {
-		if enumGrp.TypeDecl != nil {
-			enumGrp.TypeDecl.Decs.Before = dst.EmptyLine
-			decls = append(decls, enumGrp.TypeDecl)
-		}
-		// Add comment header (clear existing first to avoid duplicates)
-		enumGrp.ConstDecl.Decs.Start = nil
-		enumGrp.ConstDecl.Decs.Before = dst.EmptyLine
-		enumGrp.ConstDecl.Decs.Start.Append(fmt.Sprintf("// %s values.", enumGrp.TypeName))
-		decls = append(decls, enumGrp.ConstDecl)
-
-		// Add methods (exported first, then unexported)
-		for _, method := range enumGrp.ExportedMethods {
-			method.Decs.Before = dst.EmptyLine
-			decls = append(decls, method)
-		}
-
-		for _, method := range enumGrp.UnexportedMethods {
-			method.Decs.Before = dst.EmptyLine
-			decls = append(decls, method)
-		}
-	}
-
-	// Unexported variables (merged)
-	if len(cat.UnexportedVars) > 0 {
-		varDecl := categorize.MergeVarSpecs(cat.UnexportedVars, "unexported variables.")
-		decls = append(decls, varDecl)
-	}
-
-	// Unexported types (with constructors and methods)
-	for _, typeGrp := range cat.UnexportedTypes {
-		if typeGrp.TypeDecl != nil {
-			typeGrp.TypeDecl.Decs.Before = dst.EmptyLine
-			decls = append(decls, typeGrp.TypeDecl)
-		}
-
-		for _, ctor := range typeGrp.Constructors {
-			ctor.Decs.Before = dst.EmptyLine
-			decls = append(decls, ctor)
-		}
-
-		for _, method := range typeGrp.ExportedMethods {
-			method.Decs.Before = dst.EmptyLine
-			decls = append(decls, method)
-		}
-
-		for _, method := range typeGrp.UnexportedMethods {
-			method.Decs.Before = dst.EmptyLine
-			decls = append(decls, method)
-		}
-	}
-
-	// Unexported standalone functions
-	for _, fn := range cat.UnexportedFuncs {
-		fn.Decs.Before = dst.EmptyLine
-		decls = append(decls, fn)
-	}
-
-	return decls
+	return DeclarationsWithOrder(cat, DefaultConfig())
 }
 
 // DeclarationsWithOrder builds the ordered declaration list using config.
@@ -214,20 +64,55 @@ func DeclarationsWithOrder(cat *categorize.CategorizedDecls, cfg *Config) []dst.
 
 	// Collect uncategorized from sections not in config (if mode allows)
 	if cfg.Mode != "drop" {
-		categorize.CollectUncategorized(cat, configSections)
+		categorize.CollectUncategorized(cat, configSections, cfg.Spacing)
 	}
 
 	decls := make([]dst.Decl, 0)
 
+	// import "C" must keep its preamble, so pinned imports stay at the top in
+	// source order wherever (and whether) the order lists them
+	if cfg.PinImports {
+		decls = slices.Clone(emit.Imports(cat))
+		if cfg.Placed != nil {
+			cfg.Placed("imports", decls)
+		}
+	}
+
 	emitCfg := &emit.Config{
 		TypeLayout: cfg.TypeLayout,
 		EnumLayout: cfg.EnumLayout,
+		Spacing:    cfg.Spacing,
+	}
+
+	// The last section that emitted declarations; the imports are always
+	// followed by a blank line
+	previous := ""
+	if len(decls) > 0 {
+		previous = "imports"
 	}
 
 	for _, section := range cfg.Order {
+		if section == "imports" && cfg.PinImports {
+			continue
+		}
+
 		emitter := emit.GetEmitter(section)
-		if emitter != nil {
-			decls = append(decls, emitter(cat, emitCfg)...)
+		if emitter == nil {
+			continue
+		}
+
+		sectionDecls := emitter(cat, emitCfg)
+		if len(sectionDecls) == 0 {
+			continue
+		}
+
+		if cfg.CompactSections && previous != "" && previous != "imports" && section != "imports" {
+			categorize.JoinDecls(decls[len(decls)-1], sectionDecls[0])
+		}
+		previous = section
+		decls = append(decls, sectionDecls...)
+		if cfg.Placed != nil {
+			cfg.Placed(section, sectionDecls)
 		}
 	}
 
@@ -174,4 +174,17 @@ func ExportedFunc() {}
 			t.Errorf("expected empty, got %d", len(decls))
 		}
 	})
+
+	t.Run("pinned imports lead wherever the order lists them", func(t *testing.T) {
+		cat := categorize.CategorizeDeclarations(parseSource(t, "package test\n\nimport \"fmt\"\n\nfunc F() {}\n"))
+		cfg := &Config{Order: []string{"exported_funcs", "imports"}, PinImports: true}
+		decls := DeclarationsWithOrder(cat, cfg)
+
+		if len(decls) != 2 {
+			t.Fatalf("expected import and func, got %d", len(decls))
+		}
+		if genDecl, ok := decls[0].(*dst.GenDecl); !ok || genDecl.Tok != token.IMPORT {
+			t.Errorf("expected import first, got %T", decls[0])
+		}
+	})
 }
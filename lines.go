@@ -0,0 +1,83 @@
+package reorder
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// SourceLines reorders src like SourceFile, but only the top-level declarations
+// lying entirely within lines start to end (1-indexed, inclusive). They are
+// reordered among themselves in the lines they span, and the rest of src is
+// returned byte for byte. Declarations sharing a line are in or out together.
+// filename may be empty for source that is not a file, as with SourceWithConfig.
+//
+// Example:
+//
+//	// Tidy only the declarations on lines 40 to 120
+//	result, err := reorder.SourceLines("user.go", src, cfg, 40, 120)
+func SourceLines(filename, src string, cfg *Config, start, end int) (string, error) {
+	if start < 1 || end < start {
+		return "", fmt.Errorf("invalid line range %d:%d", start, end)
+	}
+
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse source: %w", err)
+	}
+
+	// Whole lines from the first declaration in range to the last
+	spanStart, spanEnd := -1, -1
+
+	groupStart, groupEnd, groupLine := 0, 0, 0
+	flush := func(lastLine int) {
+		if groupEnd > groupStart && groupLine >= start && lastLine <= end {
+			if spanStart < 0 {
+				spanStart = groupStart
+			}
+			spanEnd = groupEnd
+		}
+	}
+
+	lastLine := 0
+	for _, decl := range file.Decls {
+		from := fset.Position(docStart(decl))
+		to := fset.Position(decl.End())
+
+		declStart := strings.LastIndexByte(src[:from.Offset], '\n') + 1
+		declEnd := len(src)
+		if newline := strings.IndexByte(src[to.Offset:], '\n'); newline >= 0 {
+			declEnd = to.Offset + newline + 1
+		}
+
+		// A declaration starting on the line the group ends on joins it
+		if declStart < groupEnd {
+			groupEnd = max(groupEnd, declEnd)
+			lastLine = max(lastLine, to.Line)
+			continue
+		}
+
+		flush(lastLine)
+		groupStart, groupEnd, groupLine, lastLine = declStart, declEnd, from.Line, to.Line
+	}
+	flush(lastLine)
+
+	if spanStart < 0 {
+		return src, nil
+	}
+
+	// Reorder the span as a file of its own
+	clause := "package " + file.Name.Name + "\n"
+
+	result, err := SourceFile(filename, clause+"\n"+src[spanStart:spanEnd], cfg)
+	if err != nil {
+		return "", err
+	}
+
+	span := strings.TrimLeft(strings.TrimPrefix(result, clause), "\n")
+
+	return src[:spanStart] + span + src[spanEnd:], nil
+}
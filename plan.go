@@ -0,0 +1,208 @@
+package reorder
+
+import (
+	"fmt"
+	goast "go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+
+	"github.com/toejough/go-reorder/internal/ast"
+)
+
+// PlannedDecl describes where one declaration moves.
+type PlannedDecl struct {
+	Name     string // e.g. "NewUser", "(*User).Save", "Version", "fmt"; names of a spec joined with ", "
+	Kind     string // "import", "const", "var", "type", "func" or "method"
+	Section  string // Section it is placed in (e.g. "exported_funcs"), "" if dropped
+	Start    int    // Byte offset in the source, doc comment included
+	End      int    // Byte offset just past it in the source
+	Line     int    // Line of Start in the source (1-indexed)
+	NewIndex int    // Index of the declaration holding it in the result, -1 if dropped
+	NewLine  int    // Line where it starts in the result (1-indexed), 0 if dropped
+}
+
+// ReorderPlan describes where reordering moves the declarations of a file, for
+// tools that present the moves rather than the reordered source.
+type ReorderPlan struct {
+	// Decls holds the declarations in source order. Const, var and type
+	// blocks have one entry per spec, since their specs can move apart.
+	Decls []PlannedDecl
+	// Result is the reordered source, as returned by SourceWithConfig.
+	Result string
+}
+
+// Plan reorders src like SourceWithConfig and reports where each
+// declaration goes. A spec in a parenthesized block is located by the spec
+// alone, elsewhere by its whole declaration.
+//
+// Example:
+//
+//	plan, err := reorder.Plan(src, cfg)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, d := range plan.Decls {
+//	    fmt.Printf("%s: line %d -> %d\n", d.Name, d.Line, d.NewLine)
+//	}
+func Plan(src string, cfg *Config) (*ReorderPlan, error) {
+	dec, file, err := decorateFile(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse source: %w", err)
+	}
+
+	decls, nodes := plannedDecls(dec, file)
+
+	sections := make(map[dst.Decl]string)
+	ctx := fileContext{placed: func(section string, placed []dst.Decl) {
+		for _, decl := range placed {
+			sections[decl] = section
+		}
+	}}
+
+	err = fileWithContext(file, cfg, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reorder: %w", err)
+	}
+
+	result, err := printFile(file, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Declarations print in order, so the result parses back to the same list
+	fset := token.NewFileSet()
+
+	out, err := parser.ParseFile(fset, "", result, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse result: %w", err)
+	}
+
+	// Where each declaration and spec ended up; merged blocks hold the specs
+	type location struct{ decl, spec int }
+
+	locations := make(map[dst.Node]location)
+	for i, decl := range file.Decls {
+		locations[decl] = location{decl: i, spec: -1}
+		if genDecl, ok := decl.(*dst.GenDecl); ok {
+			for j, spec := range genDecl.Specs {
+				locations[spec] = location{decl: i, spec: j}
+			}
+		}
+	}
+
+	for i, node := range nodes {
+		loc, ok := locations[node]
+		if !ok || loc.decl >= len(out.Decls) {
+			decls[i].NewIndex = -1
+			continue
+		}
+
+		decls[i].NewIndex = loc.decl
+		decls[i].Section = sections[file.Decls[loc.decl]]
+		decls[i].NewLine = fset.Position(itemStart(out.Decls[loc.decl], loc.spec)).Line
+	}
+
+	return &ReorderPlan{Decls: decls, Result: result}, nil
+}
+
+// plannedDecls lists the declarations of file in source order, with the dst
+// nodes that track them through reordering.
+func plannedDecls(dec *decorator.Decorator, file *dst.File) ([]PlannedDecl, []dst.Node) {
+	var (
+		decls []PlannedDecl
+		nodes []dst.Node
+	)
+
+	add := func(node dst.Node, name, kind string, span goast.Node) {
+		start := dec.Fset.Position(docStart(span))
+		decls = append(decls, PlannedDecl{
+			Name:  name,
+			Kind:  kind,
+			Start: start.Offset,
+			End:   dec.Fset.Position(span.End()).Offset,
+			Line:  start.Line,
+		})
+		nodes = append(nodes, node)
+	}
+
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *dst.FuncDecl:
+			if decl.Recv == nil {
+				add(decl, decl.Name.Name, "func", dec.Map.Ast.Nodes[decl])
+				continue
+			}
+
+			recv := ast.ExtractReceiverTypeName(decl.Recv)
+			if ast.IsPointerReceiver(decl.Recv) {
+				recv = "(*" + recv + ")"
+			}
+			add(decl, recv+"."+decl.Name.Name, "method", dec.Map.Ast.Nodes[decl])
+		case *dst.GenDecl:
+			if decl.Tok == token.IMPORT {
+				add(decl, importPaths(decl), "import", dec.Map.Ast.Nodes[decl])
+				continue
+			}
+
+			for _, spec := range decl.Specs {
+				// A spec of a block spans itself; a lone spec its declaration
+				span := dec.Map.Ast.Nodes[decl]
+				if decl.Lparen {
+					span = dec.Map.Ast.Nodes[spec]
+				}
+				add(spec, declaredNames(spec), decl.Tok.String(), span)
+			}
+		}
+	}
+
+	return decls, nodes
+}
+
+// importPaths joins the paths of an import declaration.
+func importPaths(decl *dst.GenDecl) string {
+	paths := make([]string, 0, len(decl.Specs))
+
+	for _, spec := range decl.Specs {
+		if ispec, ok := spec.(*dst.ImportSpec); ok {
+			path, err := strconv.Unquote(ispec.Path.Value)
+			if err != nil {
+				path = ispec.Path.Value
+			}
+			paths = append(paths, path)
+		}
+	}
+
+	return strings.Join(paths, ", ")
+}
+
+// declaredNames joins the names a const, var or type spec declares.
+func declaredNames(spec dst.Spec) string {
+	switch spec := spec.(type) {
+	case *dst.TypeSpec:
+		return spec.Name.Name
+	case *dst.ValueSpec:
+		names := make([]string, 0, len(spec.Names))
+		for _, name := range spec.Names {
+			names = append(names, name.Name)
+		}
+
+		return strings.Join(names, ", ")
+	}
+
+	return ""
+}
+
+// itemStart returns where the spec-th spec of decl starts when decl is a
+// parenthesized block, or where decl starts otherwise, doc comments included.
+func itemStart(decl goast.Decl, spec int) token.Pos {
+	if genDecl, ok := decl.(*goast.GenDecl); ok && genDecl.Lparen.IsValid() && spec >= 0 && spec < len(genDecl.Specs) {
+		return docStart(genDecl.Specs[spec])
+	}
+
+	return docStart(decl)
+}
@@ -30,25 +30,34 @@
 // consts/enums/vars/types/funcs, and uncategorized. The order of these sections
 // is configurable.
 //
+// Test files (package foo_test, or files declaring Test*/Benchmark*/Fuzz* functions)
+// use Config.TestSections instead, which adds test_main, tests, benchmarks,
+// fuzz_tests, examples and test_helpers sections.
+//
 // # Type Grouping
 //
 // Types are automatically grouped with:
+//   - Assertions: Interface compliance checks (var _ io.Reader = (*Foo)(nil))
 //   - Constructors: Functions named New*TypeName (e.g., NewUser, NewMockUser)
 //   - Methods: Both exported and unexported methods on the type
 //
-// Enums (types with associated iota const blocks) are similarly grouped.
+// Enums (types with associated iota const blocks) are similarly grouped. Typed const
+// blocks without iota can also be treated as enums via Types.EnumDetection.
 package reorder
 
 import (
-	"bytes"
+	"cmp"
 	"fmt"
 	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
 	"slices"
 	"strings"
 
 	"github.com/dave/dst"
-	"github.com/dave/dst/decorator"
 
+	"github.com/toejough/go-reorder/internal/ast"
 	"github.com/toejough/go-reorder/internal/categorize"
 	"github.com/toejough/go-reorder/internal/reassemble"
 )
@@ -65,6 +74,7 @@ func (e *StrictModeError) Error() string {
 		"strict mode: code has no matching section for: %s\n"+
 			"Hints:\n"+
 			"  - Add the missing section(s) to your config's [sections] order array\n"+
+			"    (or [test_sections] for test files)\n"+
 			"  - Add \"uncategorized\" to catch any unmatched code\n"+
 			"  - Use --mode=append to be lenient (append unmatched code at end)\n"+
 			"  - Use --mode=warn to append with a warning",
@@ -87,9 +97,7 @@ type SectionOrder struct {
 // AnalyzeSectionOrder analyzes the current declaration order in source code.
 // Returns a SectionOrder showing which sections are present and their positions.
 func AnalyzeSectionOrder(src string) (*SectionOrder, error) {
-	dec := decorator.NewDecorator(token.NewFileSet())
-
-	file, err := dec.Parse(src)
+	file, err := parseFile(src)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse source: %w", err)
 	}
@@ -150,43 +158,13 @@ func AnalyzeSectionOrder(src string) (*SectionOrder, error) {
 
 // File reorders declarations in a dst.File according to project conventions.
 func File(file *dst.File) error {
-	cat := categorize.CategorizeDeclarations(file)
-	reordered := reassemble.Declarations(cat)
-	file.Decls = reordered
-
-	return nil
+	return FileWithConfig(file, DefaultConfig())
 }
 
 // FileWithConfig reorders declarations in a dst.File using the provided configuration.
 // Returns an error in strict mode if code has no matching section in the config.
 func FileWithConfig(file *dst.File, cfg *Config) error {
-	cat := categorize.CategorizeDeclarations(file)
-
-	// Build section set for checking
-	configSections := make(map[string]bool)
-	for _, s := range cfg.Sections.Order {
-		configSections[s] = true
-	}
-
-	// In strict mode, check for excluded sections before processing
-	if cfg.Behavior.Mode == "strict" {
-		excluded := categorize.FindExcludedSections(cat, configSections)
-		if len(excluded) > 0 {
-			return &StrictModeError{ExcludedSections: excluded}
-		}
-	}
-
-	reassembleCfg := &reassemble.Config{
-		Order:      cfg.Sections.Order,
-		TypeLayout: cfg.Types.TypeLayout,
-		EnumLayout: cfg.Types.EnumLayout,
-		Mode:       cfg.Behavior.Mode,
-	}
-
-	reordered := reassemble.DeclarationsWithOrder(cat, reassembleCfg)
-	file.Decls = reordered
-
-	return nil
+	return fileWithContext(file, cfg, fileContext{})
 }
 
 // Source reorders declarations in Go source code according to default conventions.
@@ -195,7 +173,7 @@ func FileWithConfig(file *dst.File, cfg *Config) error {
 // Default ordering: imports, main, init, exported (consts, enums, vars, types, funcs),
 // then unexported equivalents, then uncategorized.
 //
-// Types are grouped with their constructors (New*TypeName) and methods.
+// Types are grouped with their interface assertions, constructors (New*TypeName) and methods.
 // Enums (iota types) are grouped with their const blocks.
 //
 // Example:
@@ -206,28 +184,7 @@ func FileWithConfig(file *dst.File, cfg *Config) error {
 //	}
 //	fmt.Println(reordered)
 func Source(src string) (string, error) {
-	dec := decorator.NewDecorator(token.NewFileSet())
-
-	file, err := dec.Parse(src)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse source: %w", err)
-	}
-
-	err = File(file)
-	if err != nil {
-		return "", fmt.Errorf("failed to reorder: %w", err)
-	}
-
-	var buf bytes.Buffer
-
-	res := decorator.NewRestorer()
-
-	err = res.Fprint(&buf, file)
-	if err != nil {
-		return "", fmt.Errorf("failed to print: %w", err)
-	}
-
-	return buf.String(), nil
+	return SourceWithConfig(src, DefaultConfig())
 }
 
 // SourceWithConfig reorders declarations using the provided configuration.
@@ -246,26 +203,320 @@ func Source(src string) (string, error) {
 //	cfg.Behavior.Mode = "append"  // Don't error on unmatched code
 //	result, err := reorder.SourceWithConfig(src, cfg)
 func SourceWithConfig(src string, cfg *Config) (string, error) {
-	dec := decorator.NewDecorator(token.NewFileSet())
+	return sourceWithContext(src, cfg, fileContext{})
+}
+
+// SourceFile reorders the source of the Go file at filename using the provided
+// configuration. Unlike SourceWithConfig it knows where the file lives:
+//   - Files named *_test.go are laid out with cfg.TestSections
+//   - With cfg.Sort.Tests = "source", tests follow the position of the declaration
+//     they target in the reordered sibling file (foo.go for foo_test.go)
+//   - With cfg.Sort.Examples = "target", examples follow the position of the
+//     identifier they document in the package's reordered non-test files
+//
+// src is the content of filename; sibling and package files are read from disk.
+//
+// Example:
+//
+//	src, err := os.ReadFile("user_test.go")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	result, err := reorder.SourceFile("user_test.go", string(src), cfg)
+func SourceFile(filename, src string, cfg *Config) (string, error) {
+	ctx := fileContext{testFile: strings.HasSuffix(filename, "_test.go")}
+	if ctx.testFile && cfg.Sort.Tests == "source" {
+		ctx.testTargets = declarationPositions([]string{strings.TrimSuffix(filename, "_test.go") + ".go"}, cfg)
+	}
+	if ctx.testFile && examplesByTarget(cfg) && hasExamples(src) {
+		ctx.exampleTargets = declarationPositions(packageFiles(filepath.Dir(filename)), cfg)
+	}
+
+	return sourceWithContext(src, cfg, ctx)
+}
+
+// fileContext holds what is known about a file beyond its content.
+type fileContext struct {
+	// testFile marks the file as a test file whatever its content
+	testFile bool
+	// testTargets maps declaration keys of the code under test to their position
+	testTargets map[string]int
+	// exampleTargets maps declaration keys of the package to their position
+	exampleTargets map[string]int
+	// placed, when set, receives the declarations placed in each section
+	placed func(section string, decls []dst.Decl)
+}
+
+// fileWithContext reorders declarations in a dst.File using the provided
+// configuration and what is known about the file beyond its content.
+func fileWithContext(file *dst.File, cfg *Config, ctx fileContext) error {
+	// Grouped type declarations are split one per type unless kept
+	splitTypes := cfg.Types.Grouped != "keep"
+
+	// Comments below the last declaration stay at the end of the file
+	footer := detachTrailingComments(file, splitTypes)
+	defer attachTrailingComments(file, footer)
+
+	if cfg.Comments.Floating == "regions" {
+		return regionsWithContext(file, cfg, ctx)
+	}
+
+	// Test files use their own section order, when one is configured
+	testFile := len(cfg.TestSections.Order) > 0 && (ctx.testFile || categorize.IsTestFile(file))
+
+	order := cfg.Sections.Order
+	if testFile {
+		order = cfg.TestSections.Order
+	}
+
+	// A nil prefix list selects the default, as with EnumDetection
+	constructorPrefixes := cfg.Types.ConstructorPrefixes
+	if constructorPrefixes == nil {
+		constructorPrefixes = DefaultConfig().Types.ConstructorPrefixes
+	}
+
+	categorizeCfg := &categorize.Config{
+		AssertionsWithTypes:         slices.Contains(cfg.Types.TypeLayout, "assertions"),
+		AssertionsWithEnums:         slices.Contains(cfg.Types.EnumLayout, "assertions"),
+		IotaEnums:                   cfg.Types.EnumDetection == nil || slices.Contains(cfg.Types.EnumDetection, "iota"),
+		TypedBlockEnums:             slices.Contains(cfg.Types.EnumDetection, "typed_block"),
+		ConstructorPrefixes:         constructorPrefixes,
+		ConstructorAnyReturn:        cfg.Types.ConstructorAnyReturn,
+		ConstructorReturnsInterface: cfg.Types.ConstructorReturnsInterface,
+		OptionsWithTypes:            slices.Contains(cfg.Types.TypeLayout, "options"),
+		OptionTypePatterns:          cfg.Types.OptionTypes,
+		InterfaceMethods:            slices.Contains(cfg.Types.TypeLayout, "interface_methods"),
+		InterfaceMethodSets:         cfg.Types.Interfaces,
+		KeepTypeBlocks:              cfg.Types.Grouped == "keep",
+		SplitTypeBlockDoc:           cfg.Types.Grouped == "split_with_doc",
+		TestFile:                    testFile,
+		TestTargets:                 ctx.testTargets,
+		ExamplesByTarget:            examplesByTarget(cfg),
+		ExampleTargets:              ctx.exampleTargets,
+		ConstsByDependency:          cfg.Sort.Consts == "dependency",
+		VarsByDependency:            cfg.Sort.Vars == "dependency",
+		TypesByDependency:           cfg.Sort.Types == "dependency",
+		FuncsStepdown:               cfg.Sort.Funcs == "stepdown",
+		// Generic sections only take declarations when the order asks for them
+		ExportedGenericFuncs:   slices.Contains(order, "exported_generic_funcs"),
+		UnexportedGenericFuncs: slices.Contains(order, "unexported_generic_funcs"),
+		ExportedGenericTypes:   slices.Contains(order, "exported_generic_types"),
+		UnexportedGenericTypes: slices.Contains(order, "unexported_generic_types"),
+		CgoExports:             slices.Contains(order, "cgo_exports") && categorize.IsCgoFile(file),
+	}
+
+	// File-level directives stay at the top, and //go:generate lines keep their
+	// sequence in a block after the imports, wherever their declarations move
+	header := detachHeaderDirectives(file.Decls)
+	generates := detachGenerateDirectives(file.Decls)
+	dropEmptyDocComments(file.Decls)
+
+	var floating dst.Decorations
+	switch cfg.Comments.Floating {
+	case "drop":
+		dropFloatingComments(file.Decls, splitTypes)
+	case "keep_at_top":
+		floating = detachFloatingComments(file.Decls, splitTypes)
+	}
 
-	file, err := dec.Parse(src)
+	var initializers []*dst.ValueSpec
+	if categorizeCfg.VarsByDependency && cfg.Warn != nil {
+		initializers = sideEffectInitializers(file.Decls)
+	}
+
+	cat := categorize.CategorizeDeclarationsWithConfig(file, categorizeCfg)
+
+	// Build section set for checking
+	configSections := make(map[string]bool)
+	for _, s := range order {
+		configSections[s] = true
+	}
+
+	// In strict mode, check for excluded sections before processing
+	if cfg.Behavior.Mode == "strict" {
+		excluded := categorize.FindExcludedSections(cat, configSections)
+		if len(excluded) > 0 {
+			return &StrictModeError{ExcludedSections: excluded}
+		}
+	}
+
+	reassembleCfg := &reassemble.Config{
+		Order:           order,
+		TypeLayout:      cfg.Types.TypeLayout,
+		EnumLayout:      cfg.Types.EnumLayout,
+		Mode:            cfg.Behavior.Mode,
+		CompactSections: cfg.Spacing.CompactSections,
+		PinImports:      categorize.IsCgoFile(file),
+		Spacing: categorize.Spacing{
+			CompactGroups:       cfg.Spacing.CompactTypeGroups,
+			SpaceMultilineSpecs: cfg.Spacing.MultilineSpecs,
+		},
+		Placed: ctx.placed,
+	}
+
+	reordered := reassemble.DeclarationsWithOrder(cat, reassembleCfg)
+	attachFloatingComments(reordered, generates)
+	attachFloatingComments(reordered, floating)
+	attachHeaderDirectives(reordered, header)
+	file.Decls = reordered
+
+	if len(initializers) > 0 {
+		moved := sideEffectInitializers(reordered)
+		// Dropped specs no longer run at all; only compare the ones still present
+		kept := slices.DeleteFunc(initializers, func(spec *dst.ValueSpec) bool {
+			return !slices.Contains(moved, spec)
+		})
+		if !slices.Equal(moved, kept) {
+			cfg.Warn(fmt.Sprintf(
+				"reordering changes the initialization order of vars with side effects: %s -> %s",
+				specNames(kept), specNames(moved),
+			))
+		}
+	}
+
+	return nil
+}
+
+// regionsWithContext reorders each region of file on its own, keeping the
+// regions and their markers in source order.
+func regionsWithContext(file *dst.File, cfg *Config, ctx fileContext) error {
+	pattern := regexp.MustCompile(cmp.Or(cfg.Comments.RegionPattern, DefaultRegionPattern))
+
+	// Every region is laid out as part of the same file
+	ctx.testFile = ctx.testFile || categorize.IsTestFile(file)
+
+	inner := *cfg
+	inner.Comments.Floating = "attach"
+
+	var decls []dst.Decl
+	for _, region := range regions(file.Decls, pattern) {
+		marker := detachRegionMarker(region, pattern)
+
+		regionFile := &dst.File{Name: file.Name, Decls: slices.Clone(region)}
+		if err := fileWithContext(regionFile, &inner, ctx); err != nil {
+			return err
+		}
+
+		attachHeaderDirectives(regionFile.Decls, marker)
+		decls = append(decls, regionFile.Decls...)
+	}
+
+	file.Decls = decls
+
+	return nil
+}
+
+// sourceWithContext parses, reorders and prints src using the provided
+// configuration and what is known about the file beyond its content.
+func sourceWithContext(src string, cfg *Config, ctx fileContext) (string, error) {
+	file, err := parseFile(src)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse source: %w", err)
 	}
 
-	err = FileWithConfig(file, cfg)
+	err = fileWithContext(file, cfg, ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to reorder: %w", err)
 	}
 
-	var buf bytes.Buffer
+	return printFile(file, cfg)
+}
+
+// sideEffectInitializers returns the var specs in decls whose initializers may
+// have side effects, in declaration order.
+func sideEffectInitializers(decls []dst.Decl) []*dst.ValueSpec {
+	var specs []*dst.ValueSpec
+
+	for _, decl := range decls {
+		genDecl, ok := decl.(*dst.GenDecl)
+		if !ok || genDecl.Tok != token.VAR {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			if vspec, ok := spec.(*dst.ValueSpec); ok && slices.ContainsFunc(vspec.Values, ast.HasSideEffects) {
+				specs = append(specs, vspec)
+			}
+		}
+	}
+
+	return specs
+}
 
-	res := decorator.NewRestorer()
+// specNames joins the first name of each spec for messages.
+func specNames(specs []*dst.ValueSpec) string {
+	names := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		names = append(names, spec.Names[0].Name)
+	}
+
+	return strings.Join(names, ", ")
+}
 
-	err = res.Fprint(&buf, file)
+// declarationPositions reorders the files at paths and returns the position of
+// each declaration key (see categorize.DeclarationKeys) across the results, in
+// path order. Missing or unparseable files are skipped; if none can be read the
+// result is nil, leaving tests and examples in name order.
+func declarationPositions(paths []string, cfg *Config) map[string]int {
+	var positions map[string]int
+
+	// Code with no section of its own still has a position, and warnings are
+	// about the file being reordered, not the files consulted
+	fileCfg := *cfg
+	fileCfg.Behavior.Mode = "append"
+	fileCfg.Warn = nil
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		file, err := parseFile(content)
+		if err != nil {
+			continue
+		}
+
+		if err := FileWithConfig(file, &fileCfg); err != nil {
+			continue
+		}
+
+		if positions == nil {
+			positions = make(map[string]int)
+		}
+
+		for _, decl := range file.Decls {
+			for _, key := range categorize.DeclarationKeys(decl) {
+				if _, seen := positions[key]; !seen {
+					positions[key] = len(positions)
+				}
+			}
+		}
+	}
+
+	return positions
+}
+
+// examplesByTarget reports whether examples follow the identifiers they
+// document rather than their names.
+func examplesByTarget(cfg *Config) bool {
+	return cfg.Sort.Examples == "target"
+}
+
+// hasExamples reports whether src may declare Example functions. It only
+// spares reading the package files for test files without examples.
+func hasExamples(src string) bool {
+	return strings.Contains(src, "\nfunc Example")
+}
+
+// packageFiles returns the non-test Go files in dir, sorted by name.
+func packageFiles(dir string) []string {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
 	if err != nil {
-		return "", fmt.Errorf("failed to print: %w", err)
+		return nil
 	}
 
-	return buf.String(), nil
+	return slices.DeleteFunc(paths, func(path string) bool {
+		return strings.HasSuffix(path, "_test.go")
+	})
 }
@@ -26,9 +26,18 @@ func TestDefaultConfig(t *testing.T) {
 	if len(cfg.Sections.Order) > 13 && cfg.Sections.Order[13] != "uncategorized" {
 		t.Errorf("expected last section to be uncategorized, got %q", cfg.Sections.Order[13])
 	}
+	if len(cfg.TestSections.Order) != 20 {
+		t.Errorf("expected 20 test sections, got %d", len(cfg.TestSections.Order))
+	}
 	if cfg.Behavior.Mode != "strict" {
 		t.Errorf("expected mode to be strict, got %q", cfg.Behavior.Mode)
 	}
+	if !cfg.Types.ConstructorReturnsInterface {
+		t.Error("expected constructors returning interfaces to attach to them")
+	}
+	if cfg.Sort.Examples != "alphabetical" {
+		t.Errorf("expected example sort alphabetical, got %q", cfg.Sort.Examples)
+	}
 }
 
 func TestConfigValidation(t *testing.T) {
@@ -58,6 +67,142 @@ func TestConfigValidation(t *testing.T) {
 		}
 	})
 
+	t.Run("unknown test section errors", func(t *testing.T) {
+		cfg := reorder.DefaultConfig()
+		cfg.TestSections.Order = []string{"tests", "bogus"}
+		err := cfg.Validate()
+		if err == nil {
+			t.Error("expected error for unknown test section")
+		}
+	})
+
+	t.Run("unknown test sort errors", func(t *testing.T) {
+		cfg := reorder.DefaultConfig()
+		cfg.Sort.Tests = "random"
+		err := cfg.Validate()
+		if err == nil {
+			t.Error("expected error for unknown test sort")
+		}
+	})
+
+	t.Run("unknown var sort errors", func(t *testing.T) {
+		cfg := reorder.DefaultConfig()
+		cfg.Sort.Vars = "topological"
+		err := cfg.Validate()
+		if err == nil {
+			t.Error("expected error for unknown var sort")
+		}
+	})
+
+	t.Run("receiver method elements validate", func(t *testing.T) {
+		cfg := reorder.DefaultConfig()
+		cfg.Types.TypeLayout = []string{"typedef", "exported_value_methods", "exported_pointer_methods", "unexported_methods"}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		cfg.Types.TypeLayout = []string{"typedef", "exported_methods", "exported_pointer_methods"}
+		if err := cfg.Validate(); err == nil {
+			t.Error("expected error mixing exported_methods with exported_pointer_methods")
+		}
+	})
+
+	t.Run("interface without methods errors", func(t *testing.T) {
+		cfg := reorder.DefaultConfig()
+		cfg.Types.Interfaces = map[string][]string{"io.Reader": {}}
+		err := cfg.Validate()
+		if err == nil {
+			t.Error("expected error for interface without methods")
+		}
+	})
+
+	t.Run("unknown type sort errors", func(t *testing.T) {
+		cfg := reorder.DefaultConfig()
+		cfg.Sort.Types = "topological"
+		err := cfg.Validate()
+		if err == nil {
+			t.Error("expected error for unknown type sort")
+		}
+	})
+
+	t.Run("unknown func sort errors", func(t *testing.T) {
+		cfg := reorder.DefaultConfig()
+		cfg.Sort.Funcs = "callgraph"
+		err := cfg.Validate()
+		if err == nil {
+			t.Error("expected error for unknown func sort")
+		}
+	})
+
+	t.Run("unknown example sort errors", func(t *testing.T) {
+		cfg := reorder.DefaultConfig()
+		cfg.Sort.Examples = "source"
+		err := cfg.Validate()
+		if err == nil {
+			t.Error("expected error for unknown example sort")
+		}
+	})
+
+	t.Run("unset comment handling selects the default", func(t *testing.T) {
+		cfg := reorder.DefaultConfig()
+		cfg.Comments = reorder.CommentsConfig{}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("unset grouped type handling selects the default", func(t *testing.T) {
+		cfg := reorder.DefaultConfig()
+		cfg.Types.Grouped = ""
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("unset sorts select the defaults", func(t *testing.T) {
+		cfg := reorder.DefaultConfig()
+		cfg.Sort = reorder.SortConfig{}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("unknown grouped type handling errors", func(t *testing.T) {
+		cfg := reorder.DefaultConfig()
+		cfg.Types.Grouped = "merge"
+		err := cfg.Validate()
+		if err == nil {
+			t.Error("expected error for unknown grouped type handling")
+		}
+	})
+
+	t.Run("unknown enum detection rule errors", func(t *testing.T) {
+		cfg := reorder.DefaultConfig()
+		cfg.Types.EnumDetection = []string{"iota", "magic"}
+		err := cfg.Validate()
+		if err == nil {
+			t.Error("expected error for unknown enum detection rule")
+		}
+	})
+
+	t.Run("unknown floating comment policy errors", func(t *testing.T) {
+		cfg := reorder.DefaultConfig()
+		cfg.Comments.Floating = "move"
+		err := cfg.Validate()
+		if err == nil {
+			t.Error("expected error for unknown floating comment policy")
+		}
+	})
+
+	t.Run("invalid region pattern errors", func(t *testing.T) {
+		cfg := reorder.DefaultConfig()
+		cfg.Comments.RegionPattern = "(--"
+		err := cfg.Validate()
+		if err == nil {
+			t.Error("expected error for invalid region pattern")
+		}
+	})
+
 	t.Run("invalid mode errors", func(t *testing.T) {
 		cfg := reorder.DefaultConfig()
 		cfg.Behavior.Mode = "invalid"
@@ -132,6 +277,225 @@ mode = "append"
 		}
 	})
 
+	t.Run("loads test sections", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.toml")
+		content := `
+[test_sections]
+order = ["imports", "tests", "uncategorized"]
+`
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := reorder.LoadConfig(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(cfg.TestSections.Order) != 3 || cfg.TestSections.Order[1] != "tests" {
+			t.Errorf("expected [imports tests uncategorized], got %v", cfg.TestSections.Order)
+		}
+		if len(cfg.Sections.Order) != 14 {
+			t.Errorf("expected 14 default sections, got %d", len(cfg.Sections.Order))
+		}
+	})
+
+	t.Run("loads sort modes", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.toml")
+		content := `
+[sort]
+tests = "source"
+consts = "dependency"
+vars = "dependency"
+types = "dependency"
+funcs = "stepdown"
+`
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := reorder.LoadConfig(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Sort.Tests != "source" {
+			t.Errorf("expected test sort source, got %q", cfg.Sort.Tests)
+		}
+		if cfg.Sort.Consts != "dependency" || cfg.Sort.Vars != "dependency" {
+			t.Errorf("expected dependency const and var sorts, got %q and %q", cfg.Sort.Consts, cfg.Sort.Vars)
+		}
+		if cfg.Sort.Types != "dependency" {
+			t.Errorf("expected type sort dependency, got %q", cfg.Sort.Types)
+		}
+		if cfg.Sort.Funcs != "stepdown" {
+			t.Errorf("expected func sort stepdown, got %q", cfg.Sort.Funcs)
+		}
+	})
+
+	t.Run("loads comment policy", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.toml")
+		content := `
+[comments]
+floating = "regions"
+region_pattern = "^// region"
+`
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := reorder.LoadConfig(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Comments.Floating != "regions" {
+			t.Errorf("expected floating policy regions, got %q", cfg.Comments.Floating)
+		}
+		if cfg.Comments.RegionPattern != "^// region" {
+			t.Errorf("expected region pattern ^// region, got %q", cfg.Comments.RegionPattern)
+		}
+	})
+
+	t.Run("loads spacing", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.toml")
+		content := `
+[spacing]
+compact_sections = true
+multiline_specs = true
+`
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := reorder.LoadConfig(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !cfg.Spacing.CompactSections {
+			t.Error("expected no blank lines between sections")
+		}
+		if cfg.Spacing.CompactTypeGroups {
+			t.Error("expected type group spacing to keep its default")
+		}
+		if !cfg.Spacing.MultilineSpecs {
+			t.Error("expected blank lines around multi-line specs")
+		}
+	})
+
+	t.Run("loads format rules", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.toml")
+		content := `
+[format]
+separate_multiline_decls = true
+`
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := reorder.LoadConfig(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !cfg.Format.SeparateMultilineDecls {
+			t.Error("expected multi-line declarations to be separated")
+		}
+	})
+
+	t.Run("loads enum detection", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.toml")
+		content := `
+[types]
+enum_detection = ["iota", "typed_block"]
+`
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := reorder.LoadConfig(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(cfg.Types.EnumDetection) != 2 || cfg.Types.EnumDetection[1] != "typed_block" {
+			t.Errorf("expected [iota typed_block], got %v", cfg.Types.EnumDetection)
+		}
+	})
+
+	t.Run("loads grouped type handling", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.toml")
+		content := `
+[types]
+grouped = "keep"
+`
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := reorder.LoadConfig(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Types.Grouped != "keep" {
+			t.Errorf("expected grouped keep, got %q", cfg.Types.Grouped)
+		}
+	})
+
+	t.Run("loads constructor rules", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.toml")
+		content := `
+[types]
+constructor_prefixes = ["New", "Parse"]
+constructor_any_return = true
+constructor_returns_interface = false
+`
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := reorder.LoadConfig(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(cfg.Types.ConstructorPrefixes) != 2 || cfg.Types.ConstructorPrefixes[1] != "Parse" {
+			t.Errorf("expected [New Parse], got %v", cfg.Types.ConstructorPrefixes)
+		}
+		if !cfg.Types.ConstructorAnyReturn {
+			t.Error("expected constructor_any_return to be true")
+		}
+		if cfg.Types.ConstructorReturnsInterface {
+			t.Error("expected constructor_returns_interface = false to ignore interfaces")
+		}
+	})
+
+	t.Run("loads interface method sets", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.toml")
+		content := `
+[types]
+type_layout = ["typedef", "interface_methods", "exported_methods", "unexported_methods"]
+
+[types.interfaces]
+"driver.Valuer" = ["Value"]
+"sort.Interface" = ["Len", "Less", "Swap"]
+`
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := reorder.LoadConfig(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(cfg.Types.Interfaces) != 2 || len(cfg.Types.Interfaces["sort.Interface"]) != 3 {
+			t.Errorf("expected the two configured interfaces, got %v", cfg.Types.Interfaces)
+		}
+	})
+
 	t.Run("invalid TOML returns error", func(t *testing.T) {
 		dir := t.TempDir()
 		path := filepath.Join(dir, "config.toml")
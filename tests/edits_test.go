@@ -0,0 +1,167 @@
+package reorder_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/toejough/go-reorder"
+)
+
+// applyEdits applies non-overlapping edits in source order to src.
+func applyEdits(src string, edits []reorder.TextEdit) string {
+	for i := len(edits) - 1; i >= 0; i-- {
+		src = src[:edits[i].Start] + edits[i].NewText + src[edits[i].End:]
+	}
+
+	return src
+}
+
+func TestEdits(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+func helper() {}
+
+// Server serves.
+type Server struct{}
+
+func (s *Server) Run() {}
+
+const Version = "1.0"
+`
+
+	edits, err := reorder.SourceEdits(input, reorder.DefaultConfig())
+	if err != nil {
+		t.Fatalf("SourceEdits() error = %v", err)
+	}
+
+	expected := []reorder.TextEdit{
+		{
+			Start: 17,
+			End:   34,
+			Range: reorder.Range{
+				Start: reorder.Position{Line: 2, Character: 0},
+				End:   reorder.Position{Line: 3, Character: 0},
+			},
+			NewText: "// Exported constants.\nconst (\n\tVersion = \"1.0\"\n)\n",
+		},
+		{
+			Start: 102,
+			End:   124,
+			Range: reorder.Range{
+				Start: reorder.Position{Line: 9, Character: 0},
+				End:   reorder.Position{Line: 10, Character: 0},
+			},
+			NewText: "func helper() {}\n",
+		},
+	}
+
+	if len(edits) != len(expected) {
+		t.Fatalf("got %d edits, want %d: %+v", len(edits), len(expected), edits)
+	}
+
+	for i := range edits {
+		if edits[i] != expected[i] {
+			t.Errorf("edit %d = %+v, want %+v", i, edits[i], expected[i])
+		}
+	}
+
+	result, err := reorder.Source(input)
+	if err != nil {
+		t.Fatalf("Source() error = %v", err)
+	}
+
+	if got := applyEdits(input, edits); got != result {
+		t.Errorf("applied edits mismatch:\nGot:\n%s\n\nWant:\n%s", got, result)
+	}
+}
+
+func TestEdits_UnchangedSource(t *testing.T) {
+	t.Parallel()
+
+	input := "package example\n\nfunc Helper() {}\n"
+
+	edits, err := reorder.SourceEdits(input, reorder.DefaultConfig())
+	if err != nil {
+		t.Fatalf("SourceEdits() error = %v", err)
+	}
+
+	if len(edits) != 0 {
+		t.Errorf("expected no edits, got %+v", edits)
+	}
+}
+
+func TestEdits_UTF16Positions(t *testing.T) {
+	t.Parallel()
+
+	// The last line has no newline, so an edit ends inside it
+	input := "package example\n\nfunc b() {}\n\nfunc A() { _ = \"héllo 🙂\" }"
+
+	edits, err := reorder.SourceEdits(input, reorder.DefaultConfig())
+	if err != nil {
+		t.Fatalf("SourceEdits() error = %v", err)
+	}
+
+	if len(edits) == 0 {
+		t.Fatal("expected edits")
+	}
+
+	// "func A() { _ = \"héllo 🙂\" }" is 27 UTF-16 code units: é is one, 🙂 two
+	last := edits[len(edits)-1]
+	if want := (reorder.Position{Line: 4, Character: 27}); last.Range.End != want {
+		t.Errorf("last edit ends at %+v, want %+v", last.Range.End, want)
+	}
+	if last.End != len(input) {
+		t.Errorf("last edit ends at byte %d, want %d", last.End, len(input))
+	}
+}
+
+func TestEdits_JSON(t *testing.T) {
+	t.Parallel()
+
+	edit := reorder.TextEdit{
+		Start: 10,
+		End:   20,
+		Range: reorder.Range{
+			Start: reorder.Position{Line: 1, Character: 0},
+			End:   reorder.Position{Line: 2, Character: 0},
+		},
+		NewText: "x\n",
+	}
+
+	data, err := json.Marshal(edit)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	// The LSP TextEdit fields, plus the byte offsets
+	expected := `{"start":10,"end":20,"range":{"start":{"line":1,"character":0},"end":{"line":2,"character":0}},"newText":"x\n"}`
+	if string(data) != expected {
+		t.Errorf("JSON = %s, want %s", data, expected)
+	}
+}
+
+func TestEdits_ApplyToFixtures(t *testing.T) {
+	t.Parallel()
+
+	configs := idempotencyConfigs()
+
+	for _, src := range fixtureSources(t) {
+		for i, cfg := range configs {
+			result, err := reorder.SourceWithConfig(src, cfg)
+			if err != nil {
+				continue
+			}
+
+			edits, err := reorder.Edits(src, result)
+			if err != nil {
+				t.Fatalf("Edits() error = %v", err)
+			}
+
+			if got := applyEdits(src, edits); got != result {
+				t.Errorf("config %d: applied edits mismatch:\nGot:\n%s\n\nWant:\n%s\n\nInput:\n%s", i, got, result, src)
+			}
+		}
+	}
+}
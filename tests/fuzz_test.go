@@ -0,0 +1,185 @@
+package reorder_test
+
+import (
+	"go/ast"
+	"go/doc/comment"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/toejough/go-reorder"
+)
+
+// idempotencyConfigs returns the configs the idempotency fuzz test picks from,
+// covering the options that rewrite comments and spacing.
+func idempotencyConfigs() []*reorder.Config {
+	defaults := reorder.DefaultConfig()
+
+	sorted := reorder.DefaultConfig()
+	sorted.Behavior.Mode = "append"
+	sorted.Sort.Consts = "dependency"
+	sorted.Sort.Vars = "dependency"
+	sorted.Sort.Types = "dependency"
+	sorted.Sort.Funcs = "stepdown"
+	sorted.Types.EnumDetection = []string{"iota", "typed_block"}
+
+	compact := reorder.DefaultConfig()
+	compact.Behavior.Mode = "append"
+	compact.Spacing = reorder.SpacingConfig{CompactSections: true, CompactTypeGroups: true, MultilineSpecs: true}
+	compact.Format.SeparateMultilineDecls = true
+	compact.Types.Grouped = "split_with_doc"
+
+	regions := reorder.DefaultConfig()
+	regions.Behavior.Mode = "append"
+	regions.Comments.Floating = "regions"
+	regions.Types.Grouped = "keep"
+
+	floating := reorder.DefaultConfig()
+	floating.Behavior.Mode = "append"
+	floating.Comments.Floating = "keep_at_top"
+	floating.Types.TypeLayout = []string{
+		"typedef", "assertions", "constructors", "options", "interface_methods",
+		"exported_pointer_methods", "exported_value_methods",
+		"unexported_pointer_methods", "unexported_value_methods",
+	}
+
+	dropped := reorder.DefaultConfig()
+	dropped.Behavior.Mode = "append"
+	dropped.Comments.Floating = "drop"
+
+	return []*reorder.Config{defaults, sorted, compact, regions, floating, dropped}
+}
+
+// fixtureSources returns the Go files used as inputs by the tests in this
+// directory: every raw string literal that starts with a package clause.
+func fixtureSources(t testing.TB) []string {
+	t.Helper()
+
+	paths, err := filepath.Glob("*_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sources []string
+
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			lit, ok := node.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING || !strings.HasPrefix(lit.Value, "`") {
+				return true
+			}
+
+			src, err := strconv.Unquote(lit.Value)
+			if err == nil && strings.HasPrefix(src, "package ") {
+				sources = append(sources, src)
+			}
+
+			return true
+		})
+	}
+
+	return sources
+}
+
+func FuzzSourceWithConfig_Idempotent(f *testing.F) {
+	configs := idempotencyConfigs()
+
+	for _, src := range fixtureSources(f) {
+		for i := range configs {
+			f.Add(src, uint8(i))
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, src string, variant uint8) {
+		cfg := configs[int(variant)%len(configs)]
+
+		// Inputs go-reorder rejects (invalid Go, strict mode errors) are out of scope
+		first, err := reorder.SourceWithConfig(src, cfg)
+		if err != nil {
+			return
+		}
+
+		second, err := reorder.SourceWithConfig(first, cfg)
+		if err != nil {
+			t.Fatalf("second SourceWithConfig failed: %v\ninput:\n%s", err, first)
+		}
+
+		// Inputs gofmt itself doesn't settle on are out of scope
+		if first != second && !gofmtSettles(src) {
+			t.Skip("gofmt doesn't settle on the input")
+		}
+
+		if first != second {
+			diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(first),
+				B:        difflib.SplitLines(second),
+				FromFile: "first",
+				ToFile:   "second",
+				Context:  3,
+			})
+			t.Errorf("not idempotent (config %d):\n%s\ninput:\n%s", int(variant)%len(configs), diff, src)
+		}
+	})
+}
+
+// gofmtSettles reports whether one gofmt run leaves src in a form gofmt no
+// longer changes, counting each comment as a doc comment, since reordering may
+// move it into doc position. gofmt needs more runs for some stray semicolons,
+// comments holding form feeds and runs of backquotes in doc comments.
+func gofmtSettles(src string) bool {
+	once, err := format.Source([]byte(src))
+	if err != nil {
+		return true
+	}
+
+	twice, err := format.Source(once)
+	if err != nil || string(once) != string(twice) {
+		return false
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "", once, parser.ParseComments)
+	if err != nil {
+		return false
+	}
+
+	for _, group := range file.Comments {
+		var text strings.Builder
+		for _, c := range group.List {
+			line, ok := strings.CutPrefix(c.Text, "//")
+			if !ok {
+				continue
+			}
+			text.WriteString(strings.TrimPrefix(line, " "))
+			text.WriteString("\n")
+		}
+
+		first := docCommentText(text.String())
+		if first != docCommentText(first) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// docCommentText formats the text of a doc comment as gofmt does.
+func docCommentText(text string) string {
+	var (
+		p  comment.Parser
+		pr comment.Printer
+	)
+
+	return string(pr.Comment(p.Parse(text)))
+}
@@ -0,0 +1,181 @@
+package reorder_test
+
+import (
+	"testing"
+
+	"github.com/toejough/go-reorder"
+)
+
+const linesInput = `package example
+
+import "fmt"
+
+func zeta() {}
+
+// helper helps.
+func helper() { fmt.Println() }
+
+type Server struct{}
+
+const Version = "1.0"
+
+func (s *Server) Run() {}
+
+var debug = false
+`
+
+func TestSourceLines(t *testing.T) {
+	t.Parallel()
+
+	// Lines 7-12 hold helper, Server and Version
+	result, err := reorder.SourceLines("", linesInput, reorder.DefaultConfig(), 6, 13)
+	if err != nil {
+		t.Fatalf("SourceLines() error = %v", err)
+	}
+
+	expected := `package example
+
+import "fmt"
+
+func zeta() {}
+
+// Exported constants.
+const (
+	Version = "1.0"
+)
+
+type Server struct{}
+
+// helper helps.
+func helper() { fmt.Println() }
+
+func (s *Server) Run() {}
+
+var debug = false
+`
+
+	if result != expected {
+		t.Errorf("Result mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSourceLines_PartialDeclarationsUntouched(t *testing.T) {
+	t.Parallel()
+
+	// helper's doc comment is on line 7, so only Server and Version are in range
+	result, err := reorder.SourceLines("", linesInput, reorder.DefaultConfig(), 8, 12)
+	if err != nil {
+		t.Fatalf("SourceLines() error = %v", err)
+	}
+
+	expected := `package example
+
+import "fmt"
+
+func zeta() {}
+
+// helper helps.
+func helper() { fmt.Println() }
+
+// Exported constants.
+const (
+	Version = "1.0"
+)
+
+type Server struct{}
+
+func (s *Server) Run() {}
+
+var debug = false
+`
+
+	if result != expected {
+		t.Errorf("Result mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSourceLines_NoDeclarationsInRange(t *testing.T) {
+	t.Parallel()
+
+	// Only blank lines and part of helper
+	result, err := reorder.SourceLines("", linesInput, reorder.DefaultConfig(), 6, 7)
+	if err != nil {
+		t.Fatalf("SourceLines() error = %v", err)
+	}
+
+	if result != linesInput {
+		t.Errorf("expected source unchanged, got:\n%s", result)
+	}
+}
+
+func TestSourceLines_SharedLine(t *testing.T) {
+	t.Parallel()
+
+	// zeta shares its line with Version, which ends past the range
+	input := `package example
+
+func zeta() {}; const Version = "1.0"
+
+func helper() {}
+
+type Server struct{}
+`
+
+	result, err := reorder.SourceLines("", input, reorder.DefaultConfig(), 3, 3)
+	if err != nil {
+		t.Fatalf("SourceLines() error = %v", err)
+	}
+
+	if result == input {
+		t.Fatal("expected the declarations on line 3 to be reordered")
+	}
+
+	result, err = reorder.SourceLines("", input, reorder.DefaultConfig(), 4, 7)
+	if err != nil {
+		t.Fatalf("SourceLines() error = %v", err)
+	}
+
+	expected := `package example
+
+func zeta() {}; const Version = "1.0"
+
+type Server struct{}
+
+func helper() {}
+`
+
+	if result != expected {
+		t.Errorf("Result mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSourceLines_InvalidRange(t *testing.T) {
+	t.Parallel()
+
+	for _, lines := range [][2]int{{0, 3}, {5, 4}} {
+		if _, err := reorder.SourceLines("", linesInput, reorder.DefaultConfig(), lines[0], lines[1]); err == nil {
+			t.Errorf("expected an error for lines %d:%d", lines[0], lines[1])
+		}
+	}
+}
+
+func TestSourceLines_Idempotent(t *testing.T) {
+	t.Parallel()
+
+	cfg := reorder.DefaultConfig()
+
+	first, err := reorder.SourceLines("", linesInput, cfg, 6, 13)
+	if err != nil {
+		t.Fatalf("SourceLines() error = %v", err)
+	}
+
+	// The reordered span grew by the lines the const block and its header add
+	second, err := reorder.SourceLines("", first, cfg, 6, 16)
+	if err != nil {
+		t.Fatalf("SourceLines() error = %v", err)
+	}
+
+	if first != second {
+		t.Errorf("second pass changed the result:\nFirst:\n%s\n\nSecond:\n%s", first, second)
+	}
+}
@@ -0,0 +1,138 @@
+package reorder_test
+
+import (
+	"testing"
+
+	"github.com/toejough/go-reorder"
+)
+
+func TestPlan(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+import "fmt"
+
+// helper helps.
+func helper() {}
+
+type (
+	// User is a user.
+	User struct{}
+	order int
+)
+
+func (u *User) Save() error { return nil }
+
+const Version = "1.0"
+
+var (
+	a, b = 1, 2
+	// Debug toggles.
+	Debug = false
+)
+
+// NewUser creates a User.
+func NewUser() *User { fmt.Println(); return nil }
+`
+
+	plan, err := reorder.Plan(input, reorder.DefaultConfig())
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	result, err := reorder.Source(input)
+	if err != nil {
+		t.Fatalf("Source() error = %v", err)
+	}
+
+	if plan.Result != result {
+		t.Errorf("Result mismatch:\nGot:\n%s\n\nWant:\n%s", plan.Result, result)
+	}
+
+	expected := []reorder.PlannedDecl{
+		{Name: "fmt", Kind: "import", Section: "imports", Line: 3, NewIndex: 0, NewLine: 3},
+		{Name: "helper", Kind: "func", Section: "unexported_funcs", Line: 5, NewIndex: 8, NewLine: 31},
+		{Name: "User", Kind: "type", Section: "exported_types", Line: 9, NewIndex: 3, NewLine: 16},
+		{Name: "order", Kind: "type", Section: "unexported_types", Line: 11, NewIndex: 7, NewLine: 29},
+		{Name: "(*User).Save", Kind: "method", Section: "exported_types", Line: 14, NewIndex: 5, NewLine: 22},
+		{Name: "Version", Kind: "const", Section: "exported_consts", Line: 16, NewIndex: 1, NewLine: 7},
+		{Name: "a, b", Kind: "var", Section: "unexported_vars", Line: 19, NewIndex: 6, NewLine: 26},
+		{Name: "Debug", Kind: "var", Section: "exported_vars", Line: 20, NewIndex: 2, NewLine: 12},
+		{Name: "NewUser", Kind: "func", Section: "exported_types", Line: 24, NewIndex: 4, NewLine: 19},
+	}
+
+	if len(plan.Decls) != len(expected) {
+		t.Fatalf("got %d decls, want %d: %+v", len(plan.Decls), len(expected), plan.Decls)
+	}
+
+	spans := []string{
+		`import "fmt"`,
+		"// helper helps.\nfunc helper() {}",
+		"// User is a user.\n\tUser struct{}",
+		"order int",
+		"func (u *User) Save() error { return nil }",
+		`const Version = "1.0"`,
+		"a, b = 1, 2",
+		"// Debug toggles.\n\tDebug = false",
+		"// NewUser creates a User.\nfunc NewUser() *User { fmt.Println(); return nil }",
+	}
+
+	for i, got := range plan.Decls {
+		if span := input[got.Start:got.End]; span != spans[i] {
+			t.Errorf("decl %d spans %q, want %q", i, span, spans[i])
+		}
+
+		got.Start, got.End = 0, 0
+		if got != expected[i] {
+			t.Errorf("decl %d = %+v, want %+v", i, got, expected[i])
+		}
+	}
+}
+
+func TestPlan_Dropped(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+func helper() {}
+
+const Version = "1.0"
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.Sections.Order = []string{"imports", "exported_consts"}
+	cfg.Behavior.Mode = "drop"
+
+	plan, err := reorder.Plan(input, cfg)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	expected := []reorder.PlannedDecl{
+		{Name: "helper", Kind: "func", Start: 17, End: 33, Line: 3, NewIndex: -1},
+		{Name: "Version", Kind: "const", Section: "exported_consts", Start: 35, End: 56, Line: 5, NewIndex: 0, NewLine: 5},
+	}
+
+	if len(plan.Decls) != len(expected) {
+		t.Fatalf("got %d decls, want %d: %+v", len(plan.Decls), len(expected), plan.Decls)
+	}
+
+	for i, got := range plan.Decls {
+		if got != expected[i] {
+			t.Errorf("decl %d = %+v, want %+v", i, got, expected[i])
+		}
+	}
+}
+
+func TestPlan_StrictModeError(t *testing.T) {
+	t.Parallel()
+
+	cfg := reorder.DefaultConfig()
+	cfg.Sections.Order = []string{"imports"}
+
+	_, err := reorder.Plan("package example\n\nfunc helper() {}\n", cfg)
+	if err == nil {
+		t.Fatal("expected a strict mode error")
+	}
+}
@@ -1,6 +1,8 @@
 package reorder_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/toejough/go-reorder"
@@ -1030,6 +1032,113 @@ type Config struct{}
 	}
 }
 
+func TestSourceWithConfig_IdempotentComments(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name: "comments below the last type of a block",
+			input: `package example
+
+type (
+	Indent     string // indent
+	DepthLimit int    // depth limit
+	// type for Marshalers, declared elsewhere
+	// type for Unmarshalers, declared elsewhere
+)
+
+func (Indent) option() {}
+
+func (DepthLimit) option() {}
+`,
+		},
+		{
+			name: "floating comment between the types of a block",
+			input: `package example
+
+type (
+	// A StructType node represents a struct type.
+	StructType struct{}
+
+	// Pointer types are represented via StarExpr nodes.
+
+	// A FuncType node represents a function type.
+	FuncType struct{}
+)
+
+func (*StructType) node() {}
+`,
+		},
+		{
+			name: "floating block comment above a const block",
+			input: `package example
+
+/*
+ * Normal distribution
+ */
+
+const (
+	rn = 3.442619855899
+)
+
+func absInt32(i int32) uint32 { return uint32(i) }
+`,
+		},
+		{
+			name: "floating block comment in a var block",
+			input: `package example
+
+var (
+	/*
+	 * Top-level convenience functions
+	 */
+
+	// globalRand is the source of random numbers.
+	globalRand = newRand()
+)
+
+func newRand() int { return 4 }
+`,
+		},
+		{
+			name: "block comment below the last spec of a const block",
+			input: `package example
+
+const (
+	opRegx        = 0x90 /* 1 op, ULEB128 register */
+	opReinterpret = 0xA9
+	/* 0xE0-0xFF reserved for user-specific */
+)
+
+const (
+	opRot = 0x17
+)
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, cfg := range idempotencyConfigs() {
+				first, err := reorder.SourceWithConfig(tt.input, cfg)
+				if err != nil {
+					t.Fatalf("first SourceWithConfig failed (config %d): %v", i, err)
+				}
+
+				second, err := reorder.SourceWithConfig(first, cfg)
+				if err != nil {
+					t.Fatalf("second SourceWithConfig failed (config %d): %v", i, err)
+				}
+
+				if first != second {
+					t.Errorf("not idempotent (config %d):\n--- First ---\n%s\n--- Second ---\n%s", i, first, second)
+				}
+			}
+		})
+	}
+}
+
 // containsInOrder checks if a appears before b in s.
 func containsInOrder(s, a, b string) bool {
 	idxA := indexOf(s, a)
@@ -1237,6 +1346,19 @@ func 123InvalidName() {}
 	}
 }
 
+// TestSource_ParseErrorDoesNotPanic tests that source go/parser can only partly
+// parse is reported as a parse error.
+func TestSource_ParseErrorDoesNotPanic(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{"0", "package example\n\nfunc (", "package example\n\nvar x = "} {
+		_, err := reorder.Source(input)
+		if err == nil || !hasSubstring(err.Error(), "failed to parse source") {
+			t.Errorf("Source(%q) error = %v, want a parse error", input, err)
+		}
+	}
+}
+
 func TestStrictModeError(t *testing.T) {
 	t.Parallel()
 
@@ -1372,3 +1494,2368 @@ type (
 		t.Errorf("Expected type declarations in output.\nGot:\n%s", result)
 	}
 }
+
+func TestSource_InterfaceAssertionsWithType(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+import "io"
+
+var defaultSize = 10
+
+// Buffer must satisfy io.Reader.
+var _ io.Reader = (*Buffer)(nil)
+
+var _ io.Writer = &Buffer{}
+
+func (b *Buffer) Read(p []byte) (int, error) { return 0, nil }
+
+type Buffer struct{}
+`
+
+	expected := `package example
+
+import "io"
+
+type Buffer struct{}
+
+// Buffer must satisfy io.Reader.
+var _ io.Reader = (*Buffer)(nil)
+var _ io.Writer = &Buffer{}
+
+func (b *Buffer) Read(p []byte) (int, error) { return 0, nil }
+
+// unexported variables.
+var (
+	defaultSize = 10
+)
+`
+
+	result, err := reorder.Source(input)
+	if err != nil {
+		t.Fatalf("Source() error = %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("Source() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSourceWithConfig_AssertionsOmittedFromLayout(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+import "io"
+
+var _ io.Reader = (*Buffer)(nil)
+
+type Buffer struct{}
+`
+	cfg := reorder.DefaultConfig()
+	cfg.Types.TypeLayout = []string{"typedef", "constructors", "exported_methods", "unexported_methods"}
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	// Without the assertions element, the assertion stays with the vars
+	if !containsInOrder(result, "type Buffer struct{}", "// unexported variables.") {
+		t.Errorf("Expected assertion in the var block after the type, got:\n%s", result)
+	}
+	if !hasSubstring(result, "(*Buffer)(nil)") {
+		t.Errorf("assertion was dropped:\n%s", result)
+	}
+}
+
+func TestSourceWithConfig_TypedBlockEnum(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+const (
+	StatusOK  Status = "ok"
+	StatusErr Status = "err"
+)
+
+const MaxRetries = 3
+
+func (s Status) String() string { return string(s) }
+
+type Status string
+`
+
+	expected := `package example
+
+// Exported constants.
+const (
+	MaxRetries = 3
+)
+
+type Status string
+
+// Status values.
+const (
+	StatusOK  Status = "ok"
+	StatusErr Status = "err"
+)
+
+func (s Status) String() string { return string(s) }
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.Types.EnumDetection = []string{"iota", "typed_block"}
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+// TestSourceWithConfig_TypedConstsJoinEnum tests that with typed_block
+// detection, typed consts outside the enum's own blocks join the enum in a
+// block of their own rather than being merged with the other constants, which
+// would change the result of the next run.
+func TestSourceWithConfig_TypedConstsJoinEnum(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+const (
+	Max            = 10
+	statusA Status = "a"
+	statusB Status = "b"
+)
+
+// Default is the status to start from.
+const Default Status = "d"
+
+type Status string
+`
+
+	expected := `package example
+
+// Exported constants.
+const (
+	Max = 10
+)
+
+type Status string
+
+// Status values.
+const (
+	statusA Status = "a"
+	statusB Status = "b"
+	// Default is the status to start from.
+	Default Status = "d"
+)
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.Types.EnumDetection = []string{"iota", "typed_block"}
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+
+	second, err := reorder.SourceWithConfig(result, cfg)
+	if err != nil {
+		t.Fatalf("second SourceWithConfig failed: %v", err)
+	}
+
+	if second != result {
+		t.Errorf("second pass changed the result:\n%s", second)
+	}
+}
+
+// TestSourceWithConfig_UnsetEnumDetection tests that a config built without
+// EnumDetection still detects iota enums, and that an empty list detects none.
+func TestSourceWithConfig_UnsetEnumDetection(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+func (c Color) String() string { return "" }
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+)
+`
+
+	expected := `package example
+
+type Color int
+
+// Color values.
+const (
+	Red Color = iota
+	Green
+)
+
+func (c Color) String() string { return "" }
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.Types.EnumDetection = nil
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+
+	cfg.Types.EnumDetection = []string{}
+
+	result, err = reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if hasSubstring(result, "// Color values.") {
+		t.Errorf("expected no enum with empty EnumDetection, got:\n%s", result)
+	}
+}
+
+func TestSource_EnumWithMultipleIotaBlocks(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+func (o Op) String() string { return "" }
+
+// Reserved range.
+const (
+	OpReserved Op = iota + 100
+	OpInternal
+)
+
+type Op int
+
+const (
+	OpRead Op = iota
+	OpWrite
+)
+`
+
+	expected := `package example
+
+type Op int
+
+// Reserved range.
+const (
+	OpReserved Op = iota + 100
+	OpInternal
+)
+
+const (
+	OpRead Op = iota
+	OpWrite
+)
+
+func (o Op) String() string { return "" }
+`
+
+	result, err := reorder.Source(input)
+	if err != nil {
+		t.Fatalf("Source() error = %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("Source() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+
+	second, err := reorder.Source(result)
+	if err != nil {
+		t.Fatalf("second Source() error = %v", err)
+	}
+
+	if second != result {
+		t.Errorf("Not idempotent:\nFirst:\n%s\n\nSecond:\n%s", result, second)
+	}
+}
+
+// TestSource_EnumHeader tests that the first const block of an enum gets the
+// "<T> values." header only when it has no doc comment, above its directives.
+func TestSource_EnumHeader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		doc      string
+		expected string
+	}{
+		{name: "no comment", doc: "", expected: "// Color values.\n"},
+		{name: "doc comment kept", doc: "// Colors in paint order.\n", expected: "// Colors in paint order.\n"},
+		{
+			name:     "directive kept below the header",
+			doc:      "//nolint:gochecknoglobals\n",
+			expected: "// Color values.\n//\n//nolint:gochecknoglobals\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			input := "package example\n\n" + tt.doc + "const (\n\tRed Color = iota\n\tBlue\n)\n\ntype Color int\n"
+			expected := "package example\n\ntype Color int\n\n" + tt.expected + "const (\n\tRed Color = iota\n\tBlue\n)\n"
+
+			result, err := reorder.Source(input)
+			if err != nil {
+				t.Fatalf("Source() error = %v", err)
+			}
+
+			if result != expected {
+				t.Errorf("Source() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+			}
+
+			second, err := reorder.Source(result)
+			if err != nil {
+				t.Fatalf("second Source() error = %v", err)
+			}
+
+			if second != result {
+				t.Errorf("second pass changed the result:\n%s", second)
+			}
+		})
+	}
+}
+
+func TestSourceWithConfig_ConstructorPrefixes(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+func ParseVersion(s string) (Version, error) { return Version{}, nil }
+
+func Helper() {}
+
+type Version struct{}
+`
+	cfg := reorder.DefaultConfig()
+	cfg.Types.ConstructorPrefixes = []string{"New", "Must", "Parse"}
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	// ParseVersion is grouped with Version, ahead of the standalone Helper
+	if !containsInOrder(result, "type Version struct{}", "func ParseVersion") {
+		t.Errorf("Expected ParseVersion after Version, got:\n%s", result)
+	}
+	if !containsInOrder(result, "func ParseVersion", "func Helper") {
+		t.Errorf("Expected ParseVersion before Helper, got:\n%s", result)
+	}
+}
+
+// TestSourceWithConfig_UnsetConstructorRules tests that a config built without
+// constructor prefixes matches constructors by the default prefixes.
+func TestSourceWithConfig_UnsetConstructorRules(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+func NewServer() *Server { return &Server{} }
+
+func NewMemoryStore() Store { return nil }
+
+type Server struct{}
+
+type Store interface{ Get() }
+`
+
+	expected := `package example
+
+type Server struct{}
+
+func NewServer() *Server { return &Server{} }
+
+type Store interface{ Get() }
+
+func NewMemoryStore() Store { return nil }
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.Types = reorder.TypesConfig{
+		TypeLayout:                  cfg.Types.TypeLayout,
+		EnumLayout:                  cfg.Types.EnumLayout,
+		ConstructorReturnsInterface: true,
+	}
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+// TestSourceWithConfig_ZeroConstructorReturnsInterface tests that a config
+// built without ConstructorReturnsInterface attaches factories returning an
+// interface to the concrete type they name, unlike DefaultConfig.
+func TestSourceWithConfig_ZeroConstructorReturnsInterface(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+type Store interface{ Get() }
+
+func NewMemStore() Store { return &memStore{} }
+
+type memStore struct{}
+
+func (*memStore) Get() {}
+`
+
+	expected := `package example
+
+type Store interface{ Get() }
+
+type memStore struct{}
+
+func NewMemStore() Store { return &memStore{} }
+
+func (*memStore) Get() {}
+`
+
+	cfg := &reorder.Config{
+		Sections: reorder.DefaultConfig().Sections,
+		Types: reorder.TypesConfig{
+			TypeLayout: reorder.DefaultConfig().Types.TypeLayout,
+		},
+	}
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+
+	// DefaultConfig keeps the factory with the interface
+	result, err = reorder.SourceWithConfig(input, reorder.DefaultConfig())
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+	if !containsInOrder(result, "func NewMemStore", "type memStore") {
+		t.Errorf("Expected NewMemStore with Store, got:\n%s", result)
+	}
+}
+
+func TestSourceWithConfig_FunctionalOptions(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+func WithTimeout(d int) Option {
+	return func(s *Server) {}
+}
+
+type Server struct{}
+
+func Helper() {}
+
+type Option func(*Server)
+
+func NewServer(opts ...Option) *Server { return &Server{} }
+
+func WithLogger() Option {
+	return func(s *Server) {}
+}
+`
+
+	expected := `package example
+
+type Option func(*Server)
+
+func WithLogger() Option {
+	return func(s *Server) {}
+}
+
+func WithTimeout(d int) Option {
+	return func(s *Server) {}
+}
+
+type Server struct{}
+
+func NewServer(opts ...Option) *Server { return &Server{} }
+
+func Helper() {}
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.Types.TypeLayout = []string{"typedef", "assertions", "constructors", "options", "exported_methods", "unexported_methods"}
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSource_TestFileSections(t *testing.T) {
+	t.Parallel()
+
+	input := `package foo_test
+
+import "testing"
+
+func helper(t *testing.T) {
+	t.Helper()
+}
+
+func ExampleParse() {}
+
+func FuzzParse(f *testing.F) {}
+
+func BenchmarkParse(b *testing.B) {}
+
+func TestZeta(t *testing.T) {}
+
+var fixture = "x"
+
+func TestAlpha(t *testing.T) {
+	helper(t)
+}
+
+func TestMain(m *testing.M) {}
+
+func unrelated() {}
+`
+
+	expected := `package foo_test
+
+import "testing"
+
+func TestMain(m *testing.M) {}
+
+func TestAlpha(t *testing.T) {
+	helper(t)
+}
+
+func TestZeta(t *testing.T) {}
+
+func BenchmarkParse(b *testing.B) {}
+
+func FuzzParse(f *testing.F) {}
+
+func ExampleParse() {}
+
+// unexported variables.
+var (
+	fixture = "x"
+)
+
+func helper(t *testing.T) {
+	t.Helper()
+}
+
+func unrelated() {}
+`
+
+	result, err := reorder.Source(input)
+	if err != nil {
+		t.Fatalf("Source failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("Source() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSourceWithConfig_EmptyTestSectionsUsesSections(t *testing.T) {
+	t.Parallel()
+
+	input := `package foo_test
+
+import "testing"
+
+func TestB(t *testing.T) {}
+
+func helper(t *testing.T) {}
+
+func TestA(t *testing.T) {}
+`
+
+	expected := `package foo_test
+
+import "testing"
+
+func TestA(t *testing.T) {}
+
+func TestB(t *testing.T) {}
+
+func helper(t *testing.T) {}
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.TestSections.Order = nil
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSourceFile_TestsFollowSiblingOrder(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	sibling := `package foo
+
+func Parse() {}
+
+type User struct{}
+
+func (u User) String() string { return "" }
+
+func NewUser() *User { return nil }
+`
+	if err := os.WriteFile(filepath.Join(dir, "foo.go"), []byte(sibling), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	input := `package foo
+
+import "testing"
+
+func TestAlpha(t *testing.T) {}
+
+func TestUser_String(t *testing.T) {}
+
+func TestParseEmpty(t *testing.T) {}
+
+func TestNewUser(t *testing.T) {}
+
+func TestParse(t *testing.T) {}
+
+func TestUser(t *testing.T) {}
+`
+
+	expected := `package foo
+
+import "testing"
+
+func TestUser(t *testing.T) {}
+
+func TestNewUser(t *testing.T) {}
+
+func TestUser_String(t *testing.T) {}
+
+func TestParse(t *testing.T) {}
+
+func TestParseEmpty(t *testing.T) {}
+
+func TestAlpha(t *testing.T) {}
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.Sort.Tests = "source"
+
+	result, err := reorder.SourceFile(filepath.Join(dir, "foo_test.go"), input, cfg)
+	if err != nil {
+		t.Fatalf("SourceFile failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceFile() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSourceFile_TestFileByName(t *testing.T) {
+	t.Parallel()
+
+	// Only helpers: the content alone doesn't mark this as a test file
+	input := `package foo
+
+import "testing"
+
+func alpha() {}
+
+func newFixture(t *testing.T) string { return "" }
+
+var fixtureName = "x"
+`
+
+	expected := `package foo
+
+import "testing"
+
+// unexported variables.
+var (
+	fixtureName = "x"
+)
+
+func newFixture(t *testing.T) string { return "" }
+
+func alpha() {}
+`
+
+	result, err := reorder.SourceFile("helpers_test.go", input, reorder.DefaultConfig())
+	if err != nil {
+		t.Fatalf("SourceFile failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceFile() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSourceFile_ExamplesFollowPackageOrder(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	pkg := `package foo
+
+func Alpha() {}
+
+func (Zeta) Run() {}
+
+type Zeta struct{}
+`
+	if err := os.WriteFile(filepath.Join(dir, "foo.go"), []byte(pkg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	input := `package foo_test
+
+func ExampleAlpha() {}
+
+func ExampleZeta_Run() {}
+
+func Example_second() {}
+
+func ExampleZeta() {}
+
+func Example() {}
+`
+
+	expected := `package foo_test
+
+func Example() {}
+
+func Example_second() {}
+
+func ExampleZeta() {}
+
+func ExampleZeta_Run() {}
+
+func ExampleAlpha() {}
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.Sort.Examples = "target"
+
+	result, err := reorder.SourceFile(filepath.Join(dir, "example_test.go"), input, cfg)
+	if err != nil {
+		t.Fatalf("SourceFile failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceFile() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSourceFile_ExamplesAlphabeticalByDefault(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	pkg := `package foo
+
+type Zeta struct{}
+
+func Alpha() {}
+`
+	if err := os.WriteFile(filepath.Join(dir, "foo.go"), []byte(pkg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	input := `package foo_test
+
+func ExampleZeta() {}
+
+func Example() {}
+
+func ExampleAlpha() {}
+`
+
+	expected := `package foo_test
+
+func Example() {}
+
+func ExampleAlpha() {}
+
+func ExampleZeta() {}
+`
+
+	result, err := reorder.SourceFile(filepath.Join(dir, "example_test.go"), input, reorder.DefaultConfig())
+	if err != nil {
+		t.Fatalf("SourceFile failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceFile() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSourceWithConfig_DependencyOrderedVars(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+import "flag"
+
+var verbose = flag.Bool("v", false, "verbose output")
+
+var addr = flag.String("addr", defaultAddr, "listen address")
+
+var defaultAddr = host + ":8080"
+
+var host = "localhost"
+
+const Timeout = BaseTimeout * 2
+
+const BaseTimeout = 10
+`
+
+	expected := `package example
+
+import "flag"
+
+// Exported constants.
+const (
+	BaseTimeout = 10
+	Timeout     = BaseTimeout * 2
+)
+
+// unexported variables.
+var (
+	host        = "localhost"
+	defaultAddr = host + ":8080"
+	addr        = flag.String("addr", defaultAddr, "listen address")
+	verbose     = flag.Bool("v", false, "verbose output")
+)
+`
+
+	var warnings []string
+
+	cfg := reorder.DefaultConfig()
+	cfg.Sort.Consts = "dependency"
+	cfg.Sort.Vars = "dependency"
+	cfg.Warn = func(msg string) { warnings = append(warnings, msg) }
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+
+	// verbose and addr both call flag functions, and now run in the opposite order
+	if len(warnings) != 1 || !hasSubstring(warnings[0], "verbose, addr -> addr, verbose") {
+		t.Errorf("expected one warning about verbose and addr, got %v", warnings)
+	}
+}
+
+func TestSourceWithConfig_DependencyOrderNoWarningWhenSequenceKept(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+import "errors"
+
+var ErrA = errors.New("a")
+
+var ErrB = errors.New("b")
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.Sort.Vars = "dependency"
+	cfg.Warn = func(msg string) { t.Errorf("unexpected warning: %s", msg) }
+
+	if _, err := reorder.SourceWithConfig(input, cfg); err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+}
+
+func TestSourceWithConfig_StepdownFuncs(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+func tokenize() {}
+
+func render() {}
+
+func parse() { tokenize() }
+
+func Run() {
+	parse()
+	render()
+}
+
+func Process() { Run() }
+`
+
+	expected := `package example
+
+func Process() { Run() }
+
+func Run() {
+	parse()
+	render()
+}
+
+func parse() { tokenize() }
+
+func tokenize() {}
+
+func render() {}
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.Sort.Funcs = "stepdown"
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSourceWithConfig_DependencyOrderedTypes(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+type server struct {
+	cache *cache[entry]
+	handler
+}
+
+type handler interface{ Serve(req request) error }
+
+type request struct{ entry entry }
+
+type cache[T comparable] struct{ items map[T]entry }
+
+type entry struct{}
+`
+
+	expected := `package example
+
+type entry struct{}
+
+type cache[T comparable] struct{ items map[T]entry }
+
+type request struct{ entry entry }
+
+type handler interface{ Serve(req request) error }
+
+type server struct {
+	cache *cache[entry]
+	handler
+}
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.Sort.Types = "dependency"
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSourceWithConfig_InterfaceMethods(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+type byAge []int
+
+func (a byAge) String() string { return "" }
+
+func (a byAge) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+
+func (a byAge) Oldest() int { return 0 }
+
+func (a byAge) Less(i, j int) bool { return a[i] < a[j] }
+
+func (a byAge) Len() int { return len(a) }
+`
+
+	expected := `package example
+
+type byAge []int
+
+func (a byAge) Len() int { return len(a) }
+
+func (a byAge) Less(i, j int) bool { return a[i] < a[j] }
+
+func (a byAge) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+
+func (a byAge) String() string { return "" }
+
+func (a byAge) Oldest() int { return 0 }
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.Types.TypeLayout = []string{"typedef", "interface_methods", "exported_methods", "unexported_methods"}
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSourceWithConfig_ValueMethodsFirst(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+type Counter struct{ n int }
+
+func (c *Counter) Inc() { c.n++ }
+
+func (c Counter) Value() int { return c.n }
+
+func (c *Counter) Add(n int) { c.n += n }
+
+func (c Counter) String() string { return "" }
+`
+
+	expected := `package example
+
+type Counter struct{ n int }
+
+func (c Counter) String() string { return "" }
+
+func (c Counter) Value() int { return c.n }
+
+func (c *Counter) Add(n int) { c.n += n }
+
+func (c *Counter) Inc() { c.n++ }
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.Types.TypeLayout = []string{"typedef", "exported_value_methods", "exported_pointer_methods", "unexported_methods"}
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSourceWithConfig_GenericSections(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+func Map[T, U any](in []T, f func(T) U) []U { return nil }
+
+type Config struct{}
+
+func (l *List[T]) Push(v T) {}
+
+func Run() {}
+
+type List[T any] struct{ items []T }
+
+func NewList[T any]() *List[T] { return nil }
+
+func filter[T any](in []T) []T { return nil }
+`
+
+	expected := `package example
+
+type Config struct{}
+
+func Run() {}
+
+type List[T any] struct{ items []T }
+
+func NewList[T any]() *List[T] { return nil }
+
+func (l *List[T]) Push(v T) {}
+
+func Map[T, U any](in []T, f func(T) U) []U { return nil }
+
+func filter[T any](in []T) []T { return nil }
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.Sections.Order = []string{
+		"imports", "exported_types", "exported_funcs",
+		"exported_generic_types", "exported_generic_funcs",
+		"unexported_funcs", "uncategorized",
+	}
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+// TestSourceWithConfig_GenericInterfaceMethods tests that the methods of types
+// in the generic sections are grouped by interface too.
+func TestSourceWithConfig_GenericInterfaceMethods(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+type Set[T comparable] map[T]struct{}
+
+func (s Set[T]) Swap(i, j int) {}
+
+func (s Set[T]) Add(v T) {}
+
+func (s Set[T]) Less(i, j int) bool { return false }
+
+func (s Set[T]) Len() int { return len(s) }
+`
+
+	expected := `package example
+
+type Set[T comparable] map[T]struct{}
+
+func (s Set[T]) Len() int { return len(s) }
+
+func (s Set[T]) Less(i, j int) bool { return false }
+
+func (s Set[T]) Swap(i, j int) {}
+
+func (s Set[T]) Add(v T) {}
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.Sections.Order = []string{"imports", "exported_generic_types", "uncategorized"}
+	cfg.Types.TypeLayout = []string{"typedef", "interface_methods", "exported_methods", "unexported_methods"}
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSourceWithConfig_CgoFile(t *testing.T) {
+	t.Parallel()
+
+	input := `package main
+
+import "fmt"
+
+/*
+#include <stdio.h>
+static int twice(int x) { return 2 * x; }
+*/
+import "C"
+
+func helper() {}
+
+func Run() {}
+
+//export goCallback
+func goCallback(x C.int) C.int { return x }
+
+// Add adds.
+//
+//export Add
+func Add(a, b C.int) C.int { return a + b }
+
+func main() { fmt.Println(C.twice(2)) }
+`
+
+	expected := `package main
+
+import "fmt"
+
+/*
+#include <stdio.h>
+static int twice(int x) { return 2 * x; }
+*/
+import "C"
+
+func main() { fmt.Println(C.twice(2)) }
+
+// Add adds.
+//
+//export Add
+func Add(a, b C.int) C.int { return a + b }
+
+//export goCallback
+func goCallback(x C.int) C.int { return x }
+
+func helper() {}
+
+func Run() {}
+`
+
+	cfg := reorder.DefaultConfig()
+	// imports are left out on purpose: they stay pinned at the top
+	cfg.Sections.Order = []string{"main", "cgo_exports", "unexported_funcs", "exported_funcs"}
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSource_PreservesDirectives(t *testing.T) {
+	t.Parallel()
+
+	input := `//go:build linux
+
+package example
+
+//go:generate echo header
+
+// helper helps.
+func helper() {}
+
+//go:embed static
+var staticFS embed.FS
+
+//go:generate echo second
+type Zed int
+
+//nolint:gochecknoglobals
+var zeta = 1
+
+//go:generate echo third
+type Alpha int
+
+// Template is embedded.
+//
+//go:embed tmpl.txt
+var Template string
+
+var alpha = 2
+`
+
+	expected := `//go:build linux
+
+package example
+
+//go:generate echo header
+
+//go:generate echo second
+//go:generate echo third
+
+// Template is embedded.
+//
+//go:embed tmpl.txt
+var Template string
+
+type Alpha int
+
+type Zed int
+
+// unexported variables.
+var (
+	alpha = 2
+	//nolint:gochecknoglobals
+	zeta = 1
+)
+
+//go:embed static
+var staticFS embed.FS
+
+// helper helps.
+func helper() {}
+`
+
+	result, err := reorder.Source(input)
+	if err != nil {
+		t.Fatalf("Source failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("Source() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+// TestSource_GenerateDirectivesGathered tests that //go:generate lines are
+// gathered after the imports in source order, leaving the comments of the
+// declarations they were above alone.
+func TestSource_GenerateDirectivesGathered(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+import "fmt"
+
+// Zed is the last letter.
+//
+//go:generate stringer -type=Zed
+type Zed int
+
+func helper() { fmt.Println() }
+
+//go:generate stringer -type=Alpha
+type Alpha int
+`
+
+	expected := `package example
+
+import "fmt"
+
+//go:generate stringer -type=Zed
+//go:generate stringer -type=Alpha
+
+type Alpha int
+
+// Zed is the last letter.
+type Zed int
+
+func helper() { fmt.Println() }
+`
+
+	result, err := reorder.Source(input)
+	if err != nil {
+		t.Fatalf("Source failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("Source() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+
+	second, err := reorder.Source(result)
+	if err != nil {
+		t.Fatalf("Source failed: %v", err)
+	}
+
+	if second != result {
+		t.Errorf("second pass changed the result:\n%s", second)
+	}
+}
+
+// TestSource_GenerateDirectivesInBodies tests that //go:generate comments inside
+// function bodies stay where they are.
+func TestSource_GenerateDirectivesInBodies(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+func helper() {
+	//go:generate echo body
+	run()
+}
+
+//go:generate echo top
+func Run() {}
+
+func run() {}
+`
+
+	expected := `package example
+
+//go:generate echo top
+
+func Run() {}
+
+func helper() {
+	//go:generate echo body
+	run()
+}
+
+func run() {}
+`
+
+	result, err := reorder.Source(input)
+	if err != nil {
+		t.Fatalf("Source failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("Source() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+// TestSource_BlockCommentsKeptWhenMerging tests that the doc comment and
+// directives of a parenthesized const or var block move to its first spec when
+// the block is merged, rather than being dropped.
+func TestSource_BlockCommentsKeptWhenMerging(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "directive above a var block",
+			input: `package example
+
+//nolint:gochecknoglobals // registry
+var (
+	b = 2
+	a = 1
+)
+`,
+			expected: `package example
+
+// unexported variables.
+var (
+	a = 1
+	//nolint:gochecknoglobals // registry
+	b = 2
+)
+`,
+		},
+		{
+			name: "doc comment above a const block",
+			input: `package example
+
+func helper() {}
+
+// Limits.
+const (
+	// Max is the most.
+	Max = 10
+	Min = 1
+)
+`,
+			expected: `package example
+
+// Exported constants.
+const (
+	// Limits.
+	// Max is the most.
+	Max = 10
+	Min = 1
+)
+
+func helper() {}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := reorder.Source(tt.input)
+			if err != nil {
+				t.Fatalf("Source failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("Source() mismatch:\nGot:\n%s\n\nWant:\n%s", result, tt.expected)
+			}
+
+			second, err := reorder.Source(result)
+			if err != nil {
+				t.Fatalf("Source failed: %v", err)
+			}
+
+			if second != result {
+				t.Errorf("second pass changed the result:\n%s", second)
+			}
+		})
+	}
+}
+
+func TestSourceWithConfig_FloatingComments(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+import "fmt"
+
+// ---- HTTP helpers ----
+
+func serve() {}
+
+// Handle handles.
+func Handle() { fmt.Println() }
+
+// ---- Storage ----
+
+//go:generate echo storage
+
+func save() {}
+
+type Store struct{}
+
+func Load() {}
+`
+
+	tests := []struct {
+		floating string
+		expected string
+	}{
+		{
+			floating: "attach",
+			expected: `package example
+
+import "fmt"
+
+//go:generate echo storage
+
+type Store struct{}
+
+// Handle handles.
+func Handle() { fmt.Println() }
+
+func Load() {}
+
+// ---- Storage ----
+
+func save() {}
+
+// ---- HTTP helpers ----
+
+func serve() {}
+`,
+		},
+		{
+			floating: "drop",
+			expected: `package example
+
+import "fmt"
+
+//go:generate echo storage
+
+type Store struct{}
+
+// Handle handles.
+func Handle() { fmt.Println() }
+
+func Load() {}
+
+func save() {}
+
+func serve() {}
+`,
+		},
+		{
+			floating: "keep_at_top",
+			expected: `package example
+
+import "fmt"
+
+// ---- HTTP helpers ----
+
+// ---- Storage ----
+
+//go:generate echo storage
+
+type Store struct{}
+
+// Handle handles.
+func Handle() { fmt.Println() }
+
+func Load() {}
+
+func save() {}
+
+func serve() {}
+`,
+		},
+		{
+			floating: "regions",
+			expected: `package example
+
+import "fmt"
+
+// ---- HTTP helpers ----
+
+// Handle handles.
+func Handle() { fmt.Println() }
+
+func serve() {}
+
+// ---- Storage ----
+
+//go:generate echo storage
+
+type Store struct{}
+
+func Load() {}
+
+func save() {}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.floating, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := reorder.DefaultConfig()
+			cfg.Comments.Floating = tt.floating
+
+			result, err := reorder.SourceWithConfig(input, cfg)
+			if err != nil {
+				t.Fatalf("SourceWithConfig failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, tt.expected)
+			}
+
+			again, err := reorder.SourceWithConfig(result, cfg)
+			if err != nil {
+				t.Fatalf("second SourceWithConfig failed: %v", err)
+			}
+			if again != result {
+				t.Errorf("not idempotent:\nGot:\n%s\n\nWant:\n%s", again, result)
+			}
+		})
+	}
+}
+
+// TestSourceWithConfig_RegionsWithoutPattern tests that regions without a
+// configured pattern use the default one.
+func TestSourceWithConfig_FloatingBlockComments(t *testing.T) {
+	input := `package main
+
+import "fmt"
+
+//go:generate go run . -out out.s
+
+func main() { fmt.Println() }
+
+/* ---- Helpers ---- */
+
+/* ---- Storage ---- */
+
+func helper() {}
+`
+	expected := `package main
+
+import "fmt"
+
+/* ---- Helpers ---- */
+
+/* ---- Storage ---- */
+
+//go:generate go run . -out out.s
+
+func main() { fmt.Println() }
+
+func helper() {}
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.Comments.Floating = "keep_at_top"
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	// The line break after a block comment is not a blank line of its own
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSourceWithConfig_RegionsWithoutPattern(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+// ---- Storage ----
+
+func save() {}
+
+func Load() {}
+
+// ---- HTTP ----
+
+func serve() {}
+
+// Not a region marker.
+
+func Handle() {}
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.Comments.Floating = "regions"
+
+	expected, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	cfg.Comments.RegionPattern = ""
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+// TestSourceWithConfig_ZeroSpacing tests that a config built without spacing
+// options separates declarations and sections as before they existed.
+func TestSourceWithConfig_ZeroSpacing(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+func helper() {}
+
+func (s *Server) Start() {}
+
+type Server struct{}
+`
+
+	expected := `package example
+
+type Server struct{}
+
+func (s *Server) Start() {}
+
+func helper() {}
+`
+
+	defaults := reorder.DefaultConfig()
+	cfg := &reorder.Config{Sections: defaults.Sections, Types: defaults.Types, Behavior: defaults.Behavior}
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSourceWithConfig_Spacing(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+func helper() {}
+
+var Name = "x"
+
+var Handlers = map[string]int{
+	"a": 1,
+}
+
+var Hook = func() {
+	println()
+}
+
+func (s *Server) stop() {}
+
+// Start starts.
+func (s *Server) Start() {}
+
+func NewServer() *Server { return &Server{} }
+
+type Server struct{}
+
+func Run() {}
+`
+
+	expected := `package example
+
+// Exported variables.
+var (
+	Handlers = map[string]int{
+		"a": 1,
+	}
+
+	Hook = func() {
+		println()
+	}
+
+	Name = "x"
+)
+
+type Server struct{}
+
+func NewServer() *Server { return &Server{} }
+
+// Start starts.
+func (s *Server) Start() {}
+func (s *Server) stop()  {}
+func Run()               {}
+func helper()            {}
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.Spacing = reorder.SpacingConfig{CompactSections: true, CompactTypeGroups: true, MultilineSpecs: true}
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+// TestSourceWithConfig_CompactTypeGroupsSameKeyword tests that compact type
+// groups join only declarations gofmt doesn't separate: a typedef keeps the
+// blank line before its constructor, and an enum's const block keeps the one
+// before its methods.
+func TestSourceWithConfig_CompactTypeGroupsSameKeyword(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+func (s *Server) Stop() {}
+
+func NewServer() *Server { return &Server{} }
+
+type Server struct{}
+
+func (c Color) String() string { return "" }
+
+const (
+	Red Color = iota
+	Blue
+)
+
+type Color int
+`
+
+	expected := `package example
+
+type Color int
+
+// Color values.
+const (
+	Red Color = iota
+	Blue
+)
+
+func (c Color) String() string { return "" }
+
+type Server struct{}
+
+func NewServer() *Server { return &Server{} }
+func (s *Server) Stop()  {}
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.Spacing.CompactTypeGroups = true
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSourceWithConfig_GroupedTypes(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+// Block doc.
+type (
+	// Zed doc.
+	Zed struct{}
+	// Alpha doc.
+	Alpha int
+)
+
+func NewAlpha() Alpha { return 0 }
+
+func (z Zed) Run() {}
+
+type Middle int
+`
+
+	tests := []struct {
+		grouped  string
+		expected string
+	}{
+		{
+			grouped: "split",
+			expected: `package example
+
+// Alpha doc.
+type Alpha int
+
+func NewAlpha() Alpha { return 0 }
+
+type Middle int
+
+// Zed doc.
+type Zed struct{}
+
+func (z Zed) Run() {}
+`,
+		},
+		{
+			grouped: "split_with_doc",
+			expected: `package example
+
+// Alpha doc.
+type Alpha int
+
+func NewAlpha() Alpha { return 0 }
+
+type Middle int
+
+// Block doc.
+//
+// Zed doc.
+type Zed struct{}
+
+func (z Zed) Run() {}
+`,
+		},
+		{
+			grouped: "keep",
+			expected: `package example
+
+type Middle int
+
+// Block doc.
+type (
+	// Zed doc.
+	Zed struct{}
+	// Alpha doc.
+	Alpha int
+)
+
+func (z Zed) Run() {}
+
+func NewAlpha() Alpha { return 0 }
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.grouped, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := reorder.DefaultConfig()
+			cfg.Types.Grouped = tt.grouped
+
+			result, err := reorder.SourceWithConfig(input, cfg)
+			if err != nil {
+				t.Fatalf("SourceWithConfig failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestSourceWithConfig_KeptTypeBlockDependencyOrder tests that the groups of the
+// other types of a kept block follow it when types are sorted by dependency.
+func TestSourceWithConfig_KeptTypeBlockDependencyOrder(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+type (
+	Z struct{ a A }
+	A int
+)
+
+func NewA() A { return 0 }
+
+func (A) M() {}
+`
+
+	expected := `package example
+
+type (
+	Z struct{ a A }
+	A int
+)
+
+func NewA() A { return 0 }
+
+func (A) M() {}
+`
+
+	cfg := reorder.DefaultConfig()
+	cfg.Types.Grouped = "keep"
+	cfg.Sort.Types = "dependency"
+
+	result, err := reorder.SourceWithConfig(input, cfg)
+	if err != nil {
+		t.Fatalf("SourceWithConfig failed: %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+func TestSourceWithConfig_SeparateMultilineDecls(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+func (s *Server) Stop() {
+	s.done = true
+}
+
+func (s *Server) Name() string { return "server" }
+
+func NewServer() *Server { return &Server{} }
+
+type Server struct{ done bool }
+`
+
+	tests := []struct {
+		name     string
+		separate bool
+		expected string
+	}{
+		{
+			name:     "gofmt",
+			separate: false,
+			expected: `package example
+
+type Server struct{ done bool }
+
+func NewServer() *Server       { return &Server{} }
+func (s *Server) Name() string { return "server" }
+func (s *Server) Stop() {
+	s.done = true
+}
+`,
+		},
+		{
+			name:     "separated",
+			separate: true,
+			expected: `package example
+
+type Server struct{ done bool }
+
+func NewServer() *Server       { return &Server{} }
+func (s *Server) Name() string { return "server" }
+
+func (s *Server) Stop() {
+	s.done = true
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := reorder.DefaultConfig()
+			cfg.Spacing.CompactTypeGroups = true
+			cfg.Format.SeparateMultilineDecls = tt.separate
+
+			result, err := reorder.SourceWithConfig(input, cfg)
+			if err != nil {
+				t.Fatalf("SourceWithConfig failed: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("SourceWithConfig() mismatch:\nGot:\n%s\n\nWant:\n%s", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestSource_UntypedIotaBlockKeptIntact tests that const blocks whose values depend
+// on their position are not merged or sorted, which would change their values.
+func TestSource_UntypedIotaBlockKeptIntact(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+const (
+	B = iota
+	A
+)
+
+const C = 5
+`
+
+	expected := `package example
+
+// Exported constants.
+const (
+	C = 5
+)
+
+const (
+	B = iota
+	A
+)
+`
+
+	result, err := reorder.Source(input)
+	if err != nil {
+		t.Fatalf("Source() error = %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("Source() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+// TestSource_TrailingComments tests that a comment on its own line below a
+// declaration moves with the declaration that follows it, and that comments
+// ending the file stay at the end.
+func TestSource_TrailingComments(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+func B() {}
+// About A.
+
+func A() {}
+// End of file.
+`
+
+	expected := `package example
+
+// About A.
+
+func A() {}
+
+func B() {}
+
+// End of file.
+`
+
+	result, err := reorder.Source(input)
+	if err != nil {
+		t.Fatalf("Source() error = %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("Source() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+
+	again, err := reorder.Source(result)
+	if err != nil {
+		t.Fatalf("second Source() error = %v", err)
+	}
+
+	if again != result {
+		t.Errorf("Source() not idempotent:\nGot:\n%s\n\nWant:\n%s", again, result)
+	}
+}
+
+// TestSource_FloatingCommentsInMergedBlocks tests that comments separated from a
+// const block by a blank line survive merging, and stay separated from the specs.
+func TestSource_FloatingCommentsInMergedBlocks(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+// Limits.
+
+const (
+	// Retries.
+
+	MaxRetries = 3
+)
+
+const Delay = 1
+`
+
+	expected := `package example
+
+// Exported constants.
+const (
+	Delay = 1
+
+	// Limits.
+
+	// Retries.
+
+	MaxRetries = 3
+)
+`
+
+	result, err := reorder.Source(input)
+	if err != nil {
+		t.Fatalf("Source() error = %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("Source() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+
+	again, err := reorder.Source(result)
+	if err != nil {
+		t.Fatalf("second Source() error = %v", err)
+	}
+
+	if again != result {
+		t.Errorf("Source() not idempotent:\nGot:\n%s\n\nWant:\n%s", again, result)
+	}
+}
+
+// TestSource_ConstructorOfExternalType tests that a constructor joins the methods of
+// a type defined elsewhere whether it comes before or after them.
+func TestSource_ConstructorOfExternalType(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+func NewEngine() *Engine { return &Engine{} }
+
+func Helper() {}
+
+func (e *Engine) Start() {}
+`
+
+	expected := `package example
+
+func NewEngine() *Engine { return &Engine{} }
+
+func (e *Engine) Start() {}
+
+func Helper() {}
+`
+
+	result, err := reorder.Source(input)
+	if err != nil {
+		t.Fatalf("Source() error = %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("Source() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+// TestSource_RedeclaredType tests that a type declared twice, which does not
+// compile, keeps both declarations instead of failing to print.
+func TestSource_RedeclaredType(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+type Code string
+
+func (c Code) String() string { return string(c) }
+
+type Code int
+`
+
+	expected := `package example
+
+type Code string
+
+func (c Code) String() string { return string(c) }
+
+type Code int
+`
+
+	result, err := reorder.Source(input)
+	if err != nil {
+		t.Fatalf("Source() error = %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("Source() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}
+
+// TestSource_EnumOfExternalType tests that the methods of an enum whose type is
+// declared in another file are emitted once, with the enum.
+func TestSource_EnumOfExternalType(t *testing.T) {
+	t.Parallel()
+
+	input := `package example
+
+func (k Kind) String() string { return "" }
+
+const (
+	KindA Kind = iota
+	KindB
+)
+`
+
+	expected := `package example
+
+// Kind values.
+const (
+	KindA Kind = iota
+	KindB
+)
+
+func (k Kind) String() string { return "" }
+`
+
+	result, err := reorder.Source(input)
+	if err != nil {
+		t.Fatalf("Source() error = %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("Source() mismatch:\nGot:\n%s\n\nWant:\n%s", result, expected)
+	}
+}